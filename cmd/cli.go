@@ -1,34 +1,71 @@
 package main
 
 import (
+	"dieg0407/seace/internal/alerts"
+	"dieg0407/seace/internal/backfill"
+	"dieg0407/seace/internal/diff"
+	"dieg0407/seace/internal/followup"
+	"dieg0407/seace/internal/record"
+	"dieg0407/seace/internal/reference"
+	"dieg0407/seace/internal/run"
 	"dieg0407/seace/internal/scrapper"
+	"dieg0407/seace/internal/stats"
+	"dieg0407/seace/internal/store"
+	"dieg0407/seace/internal/webhook"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/urfave/cli/v2"
 )
 
+const backfillStateFile = "backfill-estado.json"
+
 func main() {
 	logger := log.New(os.Stderr, "[cli] ", log.LstdFlags)
 
 	var dateString string
+	var untilString string
 	var date time.Time
 	var layout = "2006-01-02"
 	var err error
 
+	var nomenclature string
+	var nomenclaturesPath string
+	var from, to string
+	var backfillDirectory string
+	var retries int
+	var diffFormat string
+	var storePath string
+	var followUpInterval time.Duration
+	var maxAttempts int
+	var statsFormat string
+	var statsLimit int
+	settings := newSettings()
+	searchSettings := newSettings()
+	backfillSettings := newSettings()
+	followUpSettings := newSettings()
+
 	app := &cli.App{
 		Name:  "scrapper",
 		Usage: "Utiliza esto para extraer información de la página",
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			&cli.StringFlag{
 				Name:        "fecha-proceso",
 				Aliases:     []string{"d"},
 				Usage:       "La fecha a procesar",
 				Destination: &dateString,
 			},
-		},
+			&cli.StringFlag{
+				Name:        "hasta",
+				Usage:       "Último día a procesar si se quiere buscar un rango desde la fecha a procesar",
+				Destination: &untilString,
+			},
+		}, settings.flags()...),
 		Action: func(*cli.Context) error {
 			if dateString == "" {
 				return fmt.Errorf("Debes proporcionar una fecha")
@@ -36,8 +73,302 @@ func main() {
 			if date, err = time.Parse(layout, dateString); err != nil {
 				return fmt.Errorf("Formato de fecha inválido, debes usar YYYY-MM-DD")
 			}
-			scrapper.Start(date)
-			return nil
+
+			window := scrapper.SingleDay(date)
+			if untilString != "" {
+				if window.To, err = time.Parse(layout, untilString); err != nil {
+					return fmt.Errorf("Formato de fecha inválido en --hasta, debes usar YYYY-MM-DD")
+				}
+				if window.To.Before(window.From) {
+					return fmt.Errorf("La fecha de --hasta no puede ser anterior a la fecha a procesar")
+				}
+			}
+
+			options, closeOptions, err := settings.options(os.Stdout, "")
+			if err != nil {
+				return err
+			}
+			defer closeOptions()
+
+			return scrapper.StartRange(window, options)
+		},
+		Commands: []*cli.Command{
+			{
+				Name:  "buscar",
+				Usage: "Extrae solo los procesos con las nomenclaturas indicadas",
+				Flags: append([]cli.Flag{
+					&cli.StringFlag{
+						Name:        "nomenclatura",
+						Aliases:     []string{"n"},
+						Usage:       "La nomenclatura del proceso, por ejemplo AS-SM-12-2024-MPL-1",
+						Destination: &nomenclature,
+					},
+					&cli.StringFlag{
+						Name:        "archivo",
+						Aliases:     []string{"a"},
+						Usage:       "Archivo con una nomenclatura por línea",
+						Destination: &nomenclaturesPath,
+					},
+				}, searchSettings.flags()...),
+				Action: func(c *cli.Context) error {
+					if err := searchSettings.inherit(c); err != nil {
+						return err
+					}
+					nomenclatures, err := readNomenclatures(nomenclature, nomenclaturesPath)
+					if err != nil {
+						return err
+					}
+
+					options, closeOptions, err := searchSettings.options(os.Stdout, "")
+					if err != nil {
+						return err
+					}
+					defer closeOptions()
+
+					return scrapper.Search(nomenclatures, options)
+				},
+			},
+			{
+				Name:  "backfill",
+				Usage: "Procesa cada día de un rango y escribe un reporte por día",
+				Flags: append([]cli.Flag{
+					&cli.StringFlag{
+						Name:        "desde",
+						Usage:       "Primer día a procesar (YYYY-MM-DD)",
+						Required:    true,
+						Destination: &from,
+					},
+					&cli.StringFlag{
+						Name:        "hasta",
+						Usage:       "Último día a procesar (YYYY-MM-DD)",
+						Required:    true,
+						Destination: &to,
+					},
+					&cli.StringFlag{
+						Name:        "directorio",
+						Usage:       "Directorio donde escribir los reportes y el estado del backfill",
+						Value:       "reports",
+						Destination: &backfillDirectory,
+					},
+					&cli.IntFlag{
+						Name:        "reintentos",
+						Usage:       "Cantidad de veces que se reintentan los días fallidos al final",
+						Value:       1,
+						Destination: &retries,
+					},
+				}, backfillSettings.flags()...),
+				Action: func(c *cli.Context) error {
+					if err := backfillSettings.inherit(c); err != nil {
+						return err
+					}
+					fromDate, err := time.Parse(layout, from)
+					if err != nil {
+						return fmt.Errorf("Formato de fecha inválido en --desde, debes usar YYYY-MM-DD")
+					}
+					toDate, err := time.Parse(layout, to)
+					if err != nil {
+						return fmt.Errorf("Formato de fecha inválido en --hasta, debes usar YYYY-MM-DD")
+					}
+					if err := os.MkdirAll(backfillDirectory, 0755); err != nil {
+						return fmt.Errorf("No se pudo crear el directorio de reportes:\n%w", err)
+					}
+
+					state, err := backfill.LoadState(filepath.Join(backfillDirectory, backfillStateFile))
+					if err != nil {
+						return err
+					}
+
+					backfillLogger := log.New(os.Stderr, "[backfill] ", log.LstdFlags)
+					return backfill.Run(fromDate, toDate, state, retries, func(date time.Time) error {
+						return scrapeDay(backfillSettings, backfillDirectory, date)
+					}, backfillLogger)
+				},
+			},
+			{
+				Name:  "seguimiento",
+				Usage: "Vuelve a revisar los procesos de la cola de seguimiento hasta que tengan ganador",
+				Flags: append([]cli.Flag{
+					&cli.DurationFlag{
+						Name:        "intervalo",
+						Usage:       "Tiempo mínimo entre dos revisiones del mismo proceso",
+						Value:       7 * 24 * time.Hour,
+						Destination: &followUpInterval,
+					},
+					&cli.IntFlag{
+						Name:        "intentos-maximos",
+						Usage:       "Revisiones sin resolverse tras las que un proceso sale de la cola",
+						Value:       8,
+						Destination: &maxAttempts,
+					},
+				}, followUpSettings.flags()...),
+				Action: func(c *cli.Context) error {
+					if err := followUpSettings.inherit(c); err != nil {
+						return err
+					}
+					if followUpSettings.queuePath == "" {
+						return fmt.Errorf("Debes indicar la cola de seguimiento con --cola")
+					}
+
+					options, closeOptions, err := followUpSettings.options(os.Stdout, "")
+					if err != nil {
+						return err
+					}
+					defer closeOptions()
+
+					return scrapper.FollowUp(options.Queue, followUpInterval, maxAttempts, options)
+				},
+			},
+			{
+				Name:  "estadisticas",
+				Usage: "Resume los procesos del almacén por entidad, ganador, objeto, moneda y departamento",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:        "almacen",
+						Usage:       "Archivo del almacén de procesos",
+						Required:    true,
+						Destination: &storePath,
+					},
+					&cli.StringFlag{
+						Name:        "desde",
+						Usage:       "Primer día de los procesos a incluir (YYYY-MM-DD)",
+						Destination: &from,
+					},
+					&cli.StringFlag{
+						Name:        "hasta",
+						Usage:       "Último día de los procesos a incluir (YYYY-MM-DD)",
+						Destination: &to,
+					},
+					&cli.StringFlag{
+						Name:        "formato",
+						Usage:       "Formato del reporte: tabla, csv o json",
+						Value:       "tabla",
+						Destination: &statsFormat,
+					},
+					&cli.IntFlag{
+						Name:        "limite",
+						Usage:       "Cantidad máxima de filas por agrupación, 0 para mostrar todas",
+						Value:       10,
+						Destination: &statsLimit,
+					},
+				},
+				Action: func(*cli.Context) error {
+					var fromDate, toDate *time.Time
+					if from != "" {
+						date, err := time.Parse(layout, from)
+						if err != nil {
+							return fmt.Errorf("Formato de fecha inválido en --desde, debes usar YYYY-MM-DD")
+						}
+						fromDate = &date
+					}
+					if to != "" {
+						date, err := time.Parse(layout, to)
+						if err != nil {
+							return fmt.Errorf("Formato de fecha inválido en --hasta, debes usar YYYY-MM-DD")
+						}
+						toDate = &date
+					}
+
+					processes, err := store.Load(storePath)
+					if err != nil {
+						return err
+					}
+
+					summary := stats.Compute(processes.Records(), fromDate, toDate, statsLimit)
+					switch statsFormat {
+					case "tabla":
+						return summary.WriteTable(os.Stdout)
+					case "csv":
+						return summary.WriteCSV(os.Stdout)
+					case "json":
+						return summary.WriteJSON(os.Stdout)
+					}
+					return fmt.Errorf("Formato de reporte inválido, debes usar tabla, csv o json")
+				},
+			},
+			{
+				Name:      "diff",
+				Usage:     "Compara dos salidas (CSV, JSON o almacén) y muestra los procesos que cambiaron",
+				ArgsUsage: "<anterior> <actual>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:        "formato",
+						Usage:       "Formato del reporte: texto o json",
+						Value:       "texto",
+						Destination: &diffFormat,
+					},
+				},
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 2 {
+						return fmt.Errorf("Debes indicar las dos salidas a comparar")
+					}
+					if diffFormat != "texto" && diffFormat != "json" {
+						return fmt.Errorf("Formato de reporte inválido, debes usar texto o json")
+					}
+
+					before, err := diff.Load(c.Args().Get(0))
+					if err != nil {
+						return err
+					}
+					after, err := diff.Load(c.Args().Get(1))
+					if err != nil {
+						return err
+					}
+
+					report := diff.Compare(before, after)
+					if diffFormat == "json" {
+						return report.WriteJSON(os.Stdout)
+					}
+					return report.WriteText(os.Stdout)
+				},
+			},
+			{
+				Name:  "historial",
+				Usage: "Muestra las versiones guardadas de un proceso y qué cambió en cada una",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:        "nomenclatura",
+						Aliases:     []string{"n"},
+						Usage:       "La nomenclatura o la clave del proceso",
+						Required:    true,
+						Destination: &nomenclature,
+					},
+					&cli.StringFlag{
+						Name:        "almacen",
+						Usage:       "Archivo del almacén de procesos",
+						Required:    true,
+						Destination: &storePath,
+					},
+					&cli.StringFlag{
+						Name:        "formato",
+						Usage:       "Formato del reporte: texto o json",
+						Value:       "texto",
+						Destination: &diffFormat,
+					},
+				},
+				Action: func(*cli.Context) error {
+					if diffFormat != "texto" && diffFormat != "json" {
+						return fmt.Errorf("Formato de reporte inválido, debes usar texto o json")
+					}
+
+					processes, err := store.Load(storePath)
+					if err != nil {
+						return err
+					}
+					versions, err := processes.Versions(nomenclature)
+					if err != nil {
+						return err
+					}
+					if len(versions) == 0 {
+						return fmt.Errorf("El proceso %s no está en el almacén", nomenclature)
+					}
+
+					history := diff.Timeline(versions)
+					if diffFormat == "json" {
+						return history.WriteJSON(os.Stdout)
+					}
+					return history.WriteText(os.Stdout)
+				},
+			},
 		},
 	}
 
@@ -45,3 +376,500 @@ func main() {
 		logger.Fatal(err)
 	}
 }
+
+// settings agrupa las opciones compartidas por todos los comandos que
+// extraen información del portal. Cada comando tiene las suyas, para que los
+// valores por defecto de un subcomando no pisen los que se dieron antes de él.
+type settings struct {
+	filters            scrapper.SearchFilters
+	output             outputOptions
+	mode               string
+	politeness         scrapper.Politeness
+	quietHours         string
+	downloadDocuments  bool
+	documentsDirectory string
+	entitiesPath       string
+	ratesPath          string
+	storePath          string
+	queuePath          string
+	watchlistPath      string
+	alertsPath         string
+	rulesPath          string
+	digestPath         string
+	webhooksPath       string
+	deliveriesPath     string
+	resultCap          int
+}
+
+func newSettings() *settings {
+	return &settings{politeness: scrapper.DefaultPoliteness()}
+}
+
+func (s *settings) flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "entidad",
+			Usage:       "Filtra por el nombre de la entidad",
+			Destination: &s.filters.Entity,
+		},
+		&cli.StringFlag{
+			Name:        "tipo-objeto",
+			Usage:       "Filtra por el objeto: Bien, Servicio, Obra o Consultoría de Obra",
+			Destination: &s.filters.ObjectType,
+		},
+		&cli.StringFlag{
+			Name:        "tipo-procedimiento",
+			Usage:       "Filtra por el tipo de procedimiento de selección",
+			Destination: &s.filters.ProcedureType,
+		},
+		&cli.StringFlag{
+			Name:        "departamento",
+			Usage:       "Filtra por el departamento de la entidad",
+			Destination: &s.filters.Department,
+		},
+		&cli.StringFlag{
+			Name:        "descripcion",
+			Usage:       "Filtra por una palabra clave de la descripción del objeto",
+			Destination: &s.filters.Description,
+		},
+		&cli.StringFlag{
+			Name:        "modo",
+			Usage:       "Tab del buscador a usar: " + strings.Join(scrapper.SearchModeNames(), ", "),
+			Value:       scrapper.DefaultSearchMode,
+			Destination: &s.mode,
+		},
+		&cli.StringFlag{
+			Name:        "formato",
+			Usage:       "Formato de salida: csv o json",
+			Value:       "csv",
+			Destination: &s.output.format,
+		},
+		&cli.BoolFlag{
+			Name:        "incluir-sin-ganador",
+			Usage:       "Incluye en la salida los procesos sin ganador con su estado",
+			Destination: &s.output.includeAll,
+		},
+		&cli.BoolFlag{
+			Name:        "columnas-extendidas",
+			Usage:       "Escribe en la salida CSV todas las columnas del item, la entidad, el valor y la identidad del proceso",
+			Destination: &s.output.extended,
+		},
+		&cli.StringFlag{
+			Name:        "participantes",
+			Usage:       "Archivo donde escribir la tabla de postores de cada proceso",
+			Destination: &s.output.participantsPath,
+		},
+		&cli.StringFlag{
+			Name:        "cronograma",
+			Usage:       "Archivo donde escribir el cronograma de cada proceso",
+			Destination: &s.output.schedulePath,
+		},
+		&cli.StringFlag{
+			Name:        "consorcios",
+			Usage:       "Archivo donde escribir los integrantes de los consorcios postores",
+			Destination: &s.output.membersPath,
+		},
+		&cli.BoolFlag{
+			Name:        "descargar-documentos",
+			Usage:       "Descarga los documentos de cada proceso y genera un manifiesto",
+			Destination: &s.downloadDocuments,
+		},
+		&cli.StringFlag{
+			Name:        "directorio-documentos",
+			Usage:       "Directorio donde guardar los documentos descargados",
+			Value:       "documentos",
+			Destination: &s.documentsDirectory,
+		},
+		&cli.StringFlag{
+			Name:        "entidades",
+			Usage:       "Tabla de referencia de entidades (nombre;ruc;ubigeo;provincia;distrito)",
+			Destination: &s.entitiesPath,
+		},
+		&cli.StringFlag{
+			Name:        "tipos-cambio",
+			Usage:       "Tabla de tipos de cambio a soles (fecha;moneda;tipo_cambio)",
+			Destination: &s.ratesPath,
+		},
+		&cli.StringFlag{
+			Name:        "almacen",
+			Usage:       "Archivo donde se guardan los procesos vistos; los que no cambiaron no se vuelven a escribir",
+			Destination: &s.storePath,
+		},
+		&cli.StringFlag{
+			Name:        "cola",
+			Usage:       "Archivo de la cola de seguimiento donde quedan los procesos sin ganador",
+			Destination: &s.queuePath,
+		},
+		&cli.StringFlag{
+			Name:        "competidores",
+			Usage:       "Lista de competidores a vigilar (nombre;ruc)",
+			Destination: &s.watchlistPath,
+		},
+		&cli.StringFlag{
+			Name:        "alertas",
+			Usage:       "Archivo donde escribir las apariciones de los competidores",
+			Destination: &s.alertsPath,
+		},
+		&cli.StringFlag{
+			Name:        "reglas",
+			Usage:       "Reglas de etiquetas por palabra clave (etiqueta;incluir;excluir)",
+			Destination: &s.rulesPath,
+		},
+		&cli.StringFlag{
+			Name:        "resumen",
+			Usage:       "Archivo donde escribir el resumen de los procesos etiquetados en la ejecución",
+			Destination: &s.digestPath,
+		},
+		&cli.StringFlag{
+			Name:        "webhooks",
+			Usage:       "Lista de webhooks que reciben los eventos de la ejecución (url;secreto;eventos)",
+			Destination: &s.webhooksPath,
+		},
+		&cli.StringFlag{
+			Name:        "entregas-webhooks",
+			Usage:       "Archivo donde se agrega cada intento de entrega a los webhooks",
+			Destination: &s.deliveriesPath,
+		},
+		&cli.IntFlag{
+			Name:        "tope-resultados",
+			Usage:       "Cantidad máxima de filas que devuelve el portal; al alcanzarla la búsqueda se parte",
+			Destination: &s.resultCap,
+		},
+		&cli.IntFlag{
+			Name:        "acciones-por-minuto",
+			Usage:       "Máximo de acciones sobre el portal por minuto",
+			Value:       s.politeness.ActionsPerMinute,
+			Destination: &s.politeness.ActionsPerMinute,
+		},
+		&cli.IntFlag{
+			Name:        "fichas-por-minuto",
+			Usage:       "Máximo de fichas abiertas por minuto",
+			Value:       s.politeness.FichasPerMinute,
+			Destination: &s.politeness.FichasPerMinute,
+		},
+		&cli.StringFlag{
+			Name:        "horas-silencio",
+			Usage:       "Rango horario HH-HH en el que no se consulta el portal",
+			Destination: &s.quietHours,
+		},
+		&cli.DurationFlag{
+			Name:        "umbral-lentitud",
+			Usage:       "Tiempo de carga a partir del cual se reduce la velocidad",
+			Value:       s.politeness.SlowLoadThreshold,
+			Destination: &s.politeness.SlowLoadThreshold,
+		},
+	}
+}
+
+// inherit toma de los comandos padres las opciones que se dieron antes del
+// subcomando, como en `--acciones-por-minuto 5 backfill`, salvo las que el
+// subcomando también recibió.
+func (s *settings) inherit(c *cli.Context) error {
+	for _, flag := range s.flags() {
+		name := flag.Names()[0]
+		if c.IsSet(name) {
+			continue
+		}
+		for _, parent := range c.Lineage()[1:] {
+			if !parent.IsSet(name) {
+				continue
+			}
+			if err := c.Set(name, fmt.Sprint(parent.Value(name))); err != nil {
+				return fmt.Errorf("No se pudo tomar la opción --%s del comando principal:\n%w", name, err)
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// options arma las opciones del scrapper escribiendo la salida principal en
+// out. Si suffix no está vacío se agrega al nombre de las tablas adicionales.
+func (s *settings) options(out io.Writer, suffix string) (scrapper.Options, func(), error) {
+	mode, err := scrapper.FindSearchMode(s.mode)
+	if err != nil {
+		return scrapper.Options{}, nil, err
+	}
+	if err := s.filters.Validate(); err != nil {
+		return scrapper.Options{}, nil, err
+	}
+	if s.politeness.QuietHours, err = scrapper.ParseQuietHours(s.quietHours); err != nil {
+		return scrapper.Options{}, nil, err
+	}
+
+	var entities *reference.Entities
+	if s.entitiesPath != "" {
+		if entities, err = reference.LoadEntities(s.entitiesPath); err != nil {
+			return scrapper.Options{}, nil, err
+		}
+	}
+
+	var rates *reference.Rates
+	if s.ratesPath != "" {
+		if rates, err = reference.LoadRates(s.ratesPath); err != nil {
+			return scrapper.Options{}, nil, err
+		}
+	}
+
+	if s.alertsPath != "" && s.watchlistPath == "" {
+		return scrapper.Options{}, nil, fmt.Errorf("Debes indicar la lista de competidores con --competidores para escribir alertas")
+	}
+	if s.digestPath != "" && s.rulesPath == "" {
+		return scrapper.Options{}, nil, fmt.Errorf("Debes indicar las reglas de etiquetas con --reglas para escribir el resumen")
+	}
+
+	var rules *alerts.Rules
+	if s.rulesPath != "" {
+		if rules, err = alerts.LoadRules(s.rulesPath); err != nil {
+			return scrapper.Options{}, nil, err
+		}
+	}
+
+	if s.deliveriesPath != "" && s.webhooksPath == "" {
+		return scrapper.Options{}, nil, fmt.Errorf("Debes indicar la lista de webhooks con --webhooks para registrar las entregas")
+	}
+	var endpoints []webhook.Endpoint
+	if s.webhooksPath != "" {
+		if endpoints, err = webhook.LoadEndpoints(s.webhooksPath); err != nil {
+			return scrapper.Options{}, nil, err
+		}
+	}
+
+	// closers cierra en orden inverso lo que se abrió, también si algo falla
+	var closers []func()
+	closeAll := func() {
+		for i := len(closers) - 1; i >= 0; i-- {
+			closers[i]()
+		}
+	}
+
+	var processes *store.Store
+	if s.storePath != "" {
+		if processes, err = store.Open(s.storePath); err != nil {
+			return scrapper.Options{}, nil, err
+		}
+		closers = append(closers, func() { processes.Close() })
+	}
+
+	var queue *followup.Queue
+	if s.queuePath != "" {
+		if queue, err = followup.LoadQueue(s.queuePath); err != nil {
+			closeAll()
+			return scrapper.Options{}, nil, err
+		}
+	}
+
+	var watchlist *alerts.Watchlist
+	var notifiers []alerts.Notifier
+	if s.watchlistPath != "" {
+		if watchlist, err = alerts.LoadWatchlist(s.watchlistPath); err != nil {
+			closeAll()
+			return scrapper.Options{}, nil, err
+		}
+		notifiers = append(notifiers, alerts.NewLogNotifier(log.New(os.Stderr, "[alertas] ", log.LstdFlags)))
+	}
+	if s.alertsPath != "" {
+		file, err := os.Create(suffixedPath(s.alertsPath, suffix))
+		if err != nil {
+			closeAll()
+			return scrapper.Options{}, nil, fmt.Errorf("No se pudo crear el archivo de alertas:\n%w", err)
+		}
+		notifier := alerts.NewCSVNotifier(file)
+		notifiers = append(notifiers, notifier)
+		closers = append(closers, func() {
+			notifier.Close()
+			file.Close()
+		})
+	}
+
+	writers, closeWriters, err := buildWriters(s.output, out, suffix)
+	if err != nil {
+		closeAll()
+		return scrapper.Options{}, nil, err
+	}
+	closers = append(closers, closeWriters)
+
+	if s.digestPath != "" {
+		file, err := os.Create(suffixedPath(s.digestPath, suffix))
+		if err != nil {
+			closeAll()
+			return scrapper.Options{}, nil, fmt.Errorf("No se pudo crear el archivo del resumen:\n%w", err)
+		}
+		digest := alerts.NewDigestWriter(file, rules)
+		writers = append(writers, digest)
+		closers = append(closers, func() {
+			digest.Close()
+			file.Close()
+		})
+	}
+
+	var reporters []run.Reporter
+	if len(endpoints) > 0 {
+		var deliveries io.Writer
+		if s.deliveriesPath != "" {
+			file, err := os.OpenFile(s.deliveriesPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+			if err != nil {
+				closeAll()
+				return scrapper.Options{}, nil, fmt.Errorf("No se pudo abrir el registro de entregas de webhooks:\n%w", err)
+			}
+			deliveries = file
+			closers = append(closers, func() { file.Close() })
+		}
+
+		dispatcher := webhook.NewDispatcher(endpoints, deliveries, log.New(os.Stderr, "[webhooks] ", log.LstdFlags))
+		closers = append(closers, func() { dispatcher.Close() })
+		reporters = append(reporters, dispatcher)
+		if dispatcher.Subscribed(webhook.EventRecord) {
+			writers = append(writers, dispatcher)
+		}
+		if dispatcher.Subscribed(webhook.EventAlert) {
+			if watchlist == nil {
+				closeAll()
+				return scrapper.Options{}, nil, fmt.Errorf("Debes indicar la lista de competidores con --competidores para enviar alertas a los webhooks")
+			}
+			notifiers = append(notifiers, dispatcher)
+		}
+	}
+
+	return scrapper.Options{
+		Politeness:         s.politeness,
+		Writers:            writers,
+		DownloadDocuments:  s.downloadDocuments,
+		DocumentsDirectory: s.documentsDirectory,
+		Entities:           entities,
+		Rates:              rates,
+		Store:              processes,
+		Queue:              queue,
+		Watchlist:          watchlist,
+		Notifiers:          notifiers,
+		Rules:              rules,
+		Reporters:          reporters,
+		Mode:               mode,
+		Filters:            s.filters,
+		ResultCap:          s.resultCap,
+	}, closeAll, nil
+}
+
+// scrapeDay escribe el reporte del día en un archivo temporal y solo lo deja
+// con su nombre final si el día se procesó completo.
+func scrapeDay(s *settings, directory string, date time.Time) error {
+	day := date.Format("2006-01-02")
+	path := filepath.Join(directory, fmt.Sprintf("reportes-%s.%s", day, s.output.format))
+	temporary := path + ".tmp"
+
+	file, err := os.Create(temporary)
+	if err != nil {
+		return fmt.Errorf("No se pudo crear el reporte del día:\n%w", err)
+	}
+
+	options, closeOptions, err := s.options(file, day)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	// El almacén se actualiza aunque el día falle, así que al reintentarlo
+	// los procesos ya vistos deben volver a escribirse en el reporte
+	options.WriteUnchanged = true
+
+	err = scrapper.Start(date, options)
+	closeOptions()
+	file.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(temporary, path)
+}
+
+func readNomenclatures(nomenclature string, path string) ([]string, error) {
+	nomenclatures := []string{}
+	if nomenclature != "" {
+		nomenclatures = append(nomenclatures, strings.TrimSpace(nomenclature))
+	}
+
+	if path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("No se pudo leer el archivo de nomenclaturas:\n%w", err)
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			nomenclatures = append(nomenclatures, line)
+		}
+	}
+
+	if len(nomenclatures) == 0 {
+		return nil, fmt.Errorf("Debes proporcionar una nomenclatura o un archivo de nomenclaturas")
+	}
+
+	return nomenclatures, nil
+}
+
+type outputOptions struct {
+	format           string
+	includeAll       bool
+	extended         bool
+	participantsPath string
+	schedulePath     string
+	membersPath      string
+}
+
+type tableOutput struct {
+	path   string
+	name   string
+	create func(io.Writer) record.Writer
+}
+
+func buildWriters(options outputOptions, out io.Writer, suffix string) ([]record.Writer, func(), error) {
+	var writers []record.Writer
+	var files []*os.File
+
+	closeWriters := func() {
+		for _, writer := range writers {
+			writer.Close()
+		}
+		for _, file := range files {
+			file.Close()
+		}
+	}
+
+	switch options.format {
+	case "csv":
+		writers = append(writers, record.NewCSVWriter(out, options.includeAll, options.extended))
+	case "json":
+		writers = append(writers, record.NewJSONWriter(out))
+	default:
+		return nil, nil, fmt.Errorf("Formato de salida inválido, debes usar csv o json")
+	}
+
+	tables := []tableOutput{
+		{options.participantsPath, "postores", func(w io.Writer) record.Writer { return record.NewParticipantsWriter(w) }},
+		{options.schedulePath, "cronograma", func(w io.Writer) record.Writer { return record.NewScheduleWriter(w) }},
+		{options.membersPath, "consorcios", func(w io.Writer) record.Writer { return record.NewMembersWriter(w) }},
+	}
+	for _, table := range tables {
+		if table.path == "" {
+			continue
+		}
+		file, err := os.Create(suffixedPath(table.path, suffix))
+		if err != nil {
+			closeWriters()
+			return nil, nil, fmt.Errorf("No se pudo crear el archivo de %s:\n%w", table.name, err)
+		}
+		files = append(files, file)
+		writers = append(writers, table.create(file))
+	}
+
+	return writers, closeWriters, nil
+}
+
+func suffixedPath(path string, suffix string) string {
+	if suffix == "" {
+		return path
+	}
+	extension := filepath.Ext(path)
+	return strings.TrimSuffix(path, extension) + "-" + suffix + extension
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+func TestReadNomenclatures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nomenclaturas.txt")
+	content := "# pendientes de noviembre\nAS-SM-12-2024-MPL-1\n\n  LP-SM-3-2024-GRL-1  \r\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		nomenclature string
+		path         string
+		want         []string
+		wantErr      bool
+	}{
+		{name: "una nomenclatura", nomenclature: " CP-SM-1-2024-MPL-1 ", want: []string{"CP-SM-1-2024-MPL-1"}},
+		{name: "archivo", path: path, want: []string{"AS-SM-12-2024-MPL-1", "LP-SM-3-2024-GRL-1"}},
+		{name: "nomenclatura y archivo", nomenclature: "CP-SM-1-2024-MPL-1", path: path, want: []string{"CP-SM-1-2024-MPL-1", "AS-SM-12-2024-MPL-1", "LP-SM-3-2024-GRL-1"}},
+		{name: "sin nomenclaturas", wantErr: true},
+		{name: "archivo inexistente", path: filepath.Join(t.TempDir(), "no-existe.txt"), wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := readNomenclatures(test.nomenclature, test.path)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("readNomenclatures() error = %v, se esperaba error: %t", err, test.wantErr)
+			}
+			if !test.wantErr && !reflect.DeepEqual(got, test.want) {
+				t.Errorf("readNomenclatures() = %q, se esperaba %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSettingsInherit(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		actions   int
+		fichas    int
+		threshold time.Duration
+		extended  bool
+	}{
+		{name: "valores por defecto", args: []string{"scrapper", "backfill"}, actions: 20, fichas: 6, threshold: 10 * time.Second},
+		{
+			name:      "opciones antes del subcomando",
+			args:      []string{"scrapper", "--acciones-por-minuto", "5", "--umbral-lentitud", "45s", "--columnas-extendidas", "backfill"},
+			actions:   5,
+			fichas:    6,
+			threshold: 45 * time.Second,
+			extended:  true,
+		},
+		{
+			name:      "el subcomando tiene prioridad",
+			args:      []string{"scrapper", "--acciones-por-minuto", "5", "backfill", "--acciones-por-minuto", "10", "--fichas-por-minuto", "2"},
+			actions:   10,
+			fichas:    2,
+			threshold: 10 * time.Second,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := newSettings()
+			sub := newSettings()
+			app := &cli.App{
+				Name:  "scrapper",
+				Flags: root.flags(),
+				Commands: []*cli.Command{{
+					Name:   "backfill",
+					Flags:  sub.flags(),
+					Action: sub.inherit,
+				}},
+			}
+			if err := app.Run(test.args); err != nil {
+				t.Fatalf("Run() = %v", err)
+			}
+
+			politeness := sub.politeness
+			if politeness.ActionsPerMinute != test.actions || politeness.FichasPerMinute != test.fichas || politeness.SlowLoadThreshold != test.threshold {
+				t.Errorf("inherit() = %+v, se esperaba %d acciones, %d fichas y umbral de %s", politeness, test.actions, test.fichas, test.threshold)
+			}
+			if sub.output.extended != test.extended {
+				t.Errorf("inherit() columnas extendidas = %t, se esperaba %t", sub.output.extended, test.extended)
+			}
+		})
+	}
+}
@@ -2,12 +2,14 @@ module dieg0407/seace
 
 go 1.23.0
 
-require github.com/tebeka/selenium v0.9.9
+require (
+	github.com/tebeka/selenium v0.9.9
+	github.com/urfave/cli/v2 v2.27.5
+)
 
 require (
 	github.com/blang/semver v3.5.1+incompatible // indirect
 	github.com/cpuguy83/go-md2man/v2 v2.0.5 // indirect
 	github.com/russross/blackfriday/v2 v2.1.0 // indirect
-	github.com/urfave/cli/v2 v2.27.5 // indirect
 	github.com/xrash/smetrics v0.0.0-20240521201337-686a1a2994c1 // indirect
 )
@@ -0,0 +1,81 @@
+package alerts
+
+import (
+	"dieg0407/seace/internal/record"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+type digestEntry struct {
+	data  record.Record
+	match Match
+}
+
+// DigestWriter junta los procesos que coinciden con las reglas durante la
+// ejecución y al cerrarse escribe un resumen agrupado por etiqueta con el
+// enlace a cada ficha.
+type DigestWriter struct {
+	out       io.Writer
+	rules     *Rules
+	started   time.Time
+	processes int
+	byTag     map[string][]digestEntry
+}
+
+func NewDigestWriter(out io.Writer, rules *Rules) *DigestWriter {
+	return &DigestWriter{out: out, rules: rules, started: time.Now(), byTag: map[string][]digestEntry{}}
+}
+
+func (w *DigestWriter) Write(r record.Record) error {
+	matches := w.rules.Matches(r)
+	if len(matches) > 0 {
+		w.processes++
+	}
+	for _, match := range matches {
+		w.byTag[match.Tag] = append(w.byTag[match.Tag], digestEntry{data: r, match: match})
+	}
+	return nil
+}
+
+func (w *DigestWriter) Close() error {
+	if _, err := fmt.Fprintf(w.out, "Resumen de oportunidades del %s\n%d procesos con coincidencias\n", w.started.Format("2006-01-02 15:04"), w.processes); err != nil {
+		return err
+	}
+
+	tags := make([]string, 0, len(w.byTag))
+	for tag := range w.byTag {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	for _, tag := range tags {
+		entries := w.byTag[tag]
+		if _, err := fmt.Fprintf(w.out, "\n## %s (%d)\n", tag, len(entries)); err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			link := entry.data.Permalink
+			if link == "" {
+				link = "sin enlace"
+			}
+			_, err := fmt.Fprintf(w.out, "- %s | %s | %s | %s %s\n  %q (%s)\n  %s\n",
+				entry.data.Nomenclature,
+				entry.data.Entity,
+				entry.data.ObjectType,
+				entry.data.Value,
+				entry.data.Currency,
+				entry.match.Text,
+				entry.match.Pattern,
+				link,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package alerts
+
+import (
+	"dieg0407/seace/internal/table"
+	"fmt"
+	"io"
+	"log"
+)
+
+var hitsHeader = []string{"Clave", "Nomenclatura", "Entidad", "Competidor", "RUC Competidor", "Rol", "Item", "Postor", "Enlace"}
+
+// Notifier recibe las alertas de los procesos nuevos o actualizados en los que
+// aparece algún competidor.
+type Notifier interface {
+	Notify(Alert) error
+	Close() error
+}
+
+// CSVNotifier escribe una fila por cada aparición de un competidor, separando
+// las columnas por `;`. La primera fila es siempre la cabecera.
+type CSVNotifier struct {
+	table *table.Writer
+}
+
+func NewCSVNotifier(out io.Writer) *CSVNotifier {
+	return &CSVNotifier{table: table.NewWriter(out, hitsHeader)}
+}
+
+func (n *CSVNotifier) Notify(alert Alert) error {
+	rows := [][]string{}
+	for _, hit := range alert.Hits {
+		rows = append(rows, []string{
+			alert.Key,
+			alert.Nomenclature,
+			alert.Entity,
+			hit.Competitor,
+			hit.RUC,
+			hit.Role,
+			fmt.Sprintf("%d", hit.Item),
+			hit.Participant,
+			alert.Permalink,
+		})
+	}
+
+	return n.table.Write(rows...)
+}
+
+func (n *CSVNotifier) Close() error {
+	return n.table.Close()
+}
+
+// LogNotifier deja cada alerta en el log de la ejecución.
+type LogNotifier struct {
+	logger *log.Logger
+}
+
+func NewLogNotifier(logger *log.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) Notify(alert Alert) error {
+	for _, hit := range alert.Hits {
+		n.logger.Printf("El competidor %s aparece como %s en el item %d de %s (%s)\n", hit.Competitor, hit.Role, hit.Item, alert.Nomenclature, alert.Entity)
+	}
+	return nil
+}
+
+func (n *LogNotifier) Close() error {
+	return nil
+}
@@ -0,0 +1,51 @@
+package alerts
+
+import (
+	"bytes"
+	"dieg0407/seace/internal/table"
+	"reflect"
+	"testing"
+)
+
+func TestCSVNotifier(t *testing.T) {
+	out := &bytes.Buffer{}
+	notifier := NewCSVNotifier(out)
+
+	alert := Alert{
+		Key:          "nomenclatura:as-1",
+		Nomenclature: "AS-1",
+		Entity:       "MUNICIPALIDAD; NORTE",
+		Permalink:    "https://example.com/ficha?id=1;jsessionid=abc",
+		Hits:         []Hit{{Competitor: `CONSORCIO "EL SOL"`, RUC: "20123456789", Role: "ganador", Item: 2, Participant: `CONSORCIO "EL SOL"`}},
+	}
+	if err := notifier.Notify(alert); err != nil {
+		t.Fatalf("Notify() = %v", err)
+	}
+	if err := notifier.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	rows := [][]string{}
+	err := table.Read(out, "las alertas", len(hitsHeader), func(line int, columns []string) error {
+		rows = append(rows, columns)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Read() = %v", err)
+	}
+
+	want := [][]string{{
+		"nomenclatura:as-1",
+		"AS-1",
+		"MUNICIPALIDAD; NORTE",
+		`CONSORCIO "EL SOL"`,
+		"20123456789",
+		"ganador",
+		"2",
+		`CONSORCIO "EL SOL"`,
+		"https://example.com/ficha?id=1;jsessionid=abc",
+	}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("las filas escritas son %q, se esperaba %q", rows, want)
+	}
+}
@@ -0,0 +1,217 @@
+package alerts
+
+import (
+	"dieg0407/seace/internal/record"
+	"dieg0407/seace/internal/table"
+	"dieg0407/seace/internal/text"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// pattern es una palabra clave o, si se escribió entre barras, una expresión
+// regular. Ambas se comparan contra el texto en minúsculas y sin tildes, así
+// que a las expresiones también se les quitan las tildes.
+type pattern struct {
+	text       string
+	expression *regexp.Regexp
+}
+
+// Rule asigna una etiqueta a los procesos cuyo objeto o descripción de algún
+// item contiene alguno de sus patrones y ninguna de sus exclusiones.
+type Rule struct {
+	Tag      string
+	includes []pattern
+	excludes []pattern
+}
+
+// Match es una regla que coincidió con un texto del proceso.
+type Match struct {
+	Tag     string `json:"etiqueta"`
+	Pattern string `json:"patron"`
+	Text    string `json:"texto"`
+}
+
+type Rules struct {
+	rules []Rule
+}
+
+// LoadRules lee las reglas con las columnas etiqueta;incluir;excluir. Los
+// patrones de una columna se separan con `|`; los que van entre barras, como
+// /laptops?/, son expresiones regulares.
+func LoadRules(path string) (*Rules, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo abrir las reglas de etiquetas:\n%w", err)
+	}
+	defer file.Close()
+
+	return parseRules(file)
+}
+
+func parseRules(input io.Reader) (*Rules, error) {
+	rules := &Rules{}
+
+	err := table.Read(input, "las reglas de etiquetas", 3, func(line int, columns []string) error {
+		rule := Rule{Tag: columns[0]}
+		var err error
+		if rule.includes, err = parsePatterns(columns[1]); err != nil {
+			return fmt.Errorf("la línea %d de las reglas tiene un patrón inválido:\n%w", line, err)
+		}
+		if rule.excludes, err = parsePatterns(columns[2]); err != nil {
+			return fmt.Errorf("la línea %d de las reglas tiene una exclusión inválida:\n%w", line, err)
+		}
+		if rule.Tag == "" || len(rule.includes) == 0 {
+			return fmt.Errorf("la línea %d de las reglas no tiene etiqueta y patrones", line)
+		}
+		rules.rules = append(rules.rules, rule)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func parsePatterns(column string) ([]pattern, error) {
+	texts, err := splitPatterns(column)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns := []pattern{}
+	for _, source := range texts {
+		if strings.HasPrefix(source, "/") {
+			expression, err := regexp.Compile("(?i)" + text.RemoveAccents(source[1:len(source)-1]))
+			if err != nil {
+				return nil, err
+			}
+			patterns = append(patterns, pattern{text: source, expression: expression})
+			continue
+		}
+		patterns = append(patterns, pattern{text: source})
+	}
+	return patterns, nil
+}
+
+// splitPatterns separa la columna por `|`, salvo dentro de una expresión
+// regular: una expresión empieza con `/` y termina en la primera `/` seguida
+// del fin de la columna o de un `|`, así que puede usar alternativas.
+func splitPatterns(column string) ([]string, error) {
+	texts := []string{}
+	rest := strings.TrimSpace(column)
+	for rest != "" {
+		end := strings.Index(rest, "|")
+		if strings.HasPrefix(rest, "/") {
+			end = expressionEnd(rest)
+			if end < 0 {
+				return nil, fmt.Errorf("la expresión %s no tiene la barra de cierre", rest)
+			}
+		}
+		if end < 0 {
+			end = len(rest)
+		}
+
+		if text := strings.TrimSpace(rest[:end]); text != "" {
+			texts = append(texts, text)
+		}
+		if end == len(rest) {
+			break
+		}
+		rest = strings.TrimSpace(rest[end+1:])
+	}
+	return texts, nil
+}
+
+// expressionEnd devuelve la posición del `|` que sigue a la expresión con la
+// que empieza text, o el largo de text si la expresión llega hasta el final.
+// Si la expresión no tiene barra de cierre devuelve -1.
+func expressionEnd(text string) int {
+	for i := 2; i < len(text); i++ {
+		if text[i] != '/' {
+			continue
+		}
+		after := strings.TrimLeft(text[i+1:], " ")
+		if after == "" {
+			return len(text)
+		}
+		if strings.HasPrefix(after, "|") {
+			return len(text) - len(after)
+		}
+	}
+	return -1
+}
+
+func (p pattern) matches(normalized string) bool {
+	if p.expression != nil {
+		return p.expression.MatchString(normalized)
+	}
+	return strings.Contains(normalized, record.Normalize(p.text))
+}
+
+// Matches aplica las reglas al objeto y a la descripción de cada item. Una
+// exclusión descarta solo el texto en el que aparece.
+func (r *Rules) Matches(data record.Record) []Match {
+	if r == nil {
+		return nil
+	}
+
+	texts := []string{data.ObjectType}
+	for _, item := range data.Items {
+		texts = append(texts, item.Description)
+	}
+
+	matches := []Match{}
+	for _, rule := range r.rules {
+		if match, ok := rule.match(texts); ok {
+			matches = append(matches, match)
+		}
+	}
+	return matches
+}
+
+func (r Rule) match(texts []string) (Match, bool) {
+	for _, text := range texts {
+		normalized := record.Normalize(text)
+		if normalized == "" || r.excluded(normalized) {
+			continue
+		}
+		for _, include := range r.includes {
+			if include.matches(normalized) {
+				return Match{Tag: r.Tag, Pattern: include.text, Text: text}, true
+			}
+		}
+	}
+	return Match{}, false
+}
+
+func (r Rule) excluded(normalized string) bool {
+	for _, exclude := range r.excludes {
+		if exclude.matches(normalized) {
+			return true
+		}
+	}
+	return false
+}
+
+// Tag guarda en el registro las etiquetas de las reglas que coinciden,
+// ordenadas y sin repetir.
+func (r *Rules) Tag(data *record.Record) {
+	if r == nil {
+		return
+	}
+
+	seen := map[string]bool{}
+	data.Tags = []string{}
+	for _, match := range r.Matches(*data) {
+		if !seen[match.Tag] {
+			seen[match.Tag] = true
+			data.Tags = append(data.Tags, match.Tag)
+		}
+	}
+	sort.Strings(data.Tags)
+}
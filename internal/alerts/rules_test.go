@@ -0,0 +1,174 @@
+package alerts
+
+import (
+	"dieg0407/seace/internal/record"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		table    string
+		includes []string
+		excludes []string
+		wantErr  bool
+	}{
+		{
+			name:     "palabras y expresiones",
+			table:    "etiqueta;incluir;excluir\ncomputo; laptop | /computadoras?/ ;alquiler\n",
+			includes: []string{"laptop", "/computadoras?/"},
+			excludes: []string{"alquiler"},
+		},
+		{
+			name:     "patrones vacíos",
+			table:    "etiqueta;incluir;excluir\ncomputo;laptop||;\n",
+			includes: []string{"laptop"},
+			excludes: []string{},
+		},
+		{
+			name:     "expresión con alternativas",
+			table:    "etiqueta;incluir;excluir\ncomputo;/(laptop|notebook)s?/ | impresora|/pc|cpu/;/alquiler|mantenimiento/\n",
+			includes: []string{"/(laptop|notebook)s?/", "impresora", "/pc|cpu/"},
+			excludes: []string{"/alquiler|mantenimiento/"},
+		},
+		{name: "expresión sin barra de cierre", table: "etiqueta;incluir;excluir\ncomputo;/(laptop|notebook)s?;\n", wantErr: true},
+		{name: "expresión inválida", table: "etiqueta;incluir;excluir\ncomputo;/laptop(/;\n", wantErr: true},
+		{name: "exclusión inválida", table: "etiqueta;incluir;excluir\ncomputo;laptop;/[/\n", wantErr: true},
+		{name: "sin etiqueta", table: "etiqueta;incluir;excluir\n;laptop;\n", wantErr: true},
+		{name: "sin patrones", table: "etiqueta;incluir;excluir\ncomputo;|;\n", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rules, err := parseRules(strings.NewReader(test.table))
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("parseRules() = %+v, se esperaba un error", rules)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rules.rules) != 1 {
+				t.Fatalf("parseRules() leyó %d reglas, se esperaba 1", len(rules.rules))
+			}
+			if got := patternTexts(rules.rules[0].includes); !reflect.DeepEqual(got, test.includes) {
+				t.Errorf("patrones = %v, se esperaba %v", got, test.includes)
+			}
+			if got := patternTexts(rules.rules[0].excludes); !reflect.DeepEqual(got, test.excludes) {
+				t.Errorf("exclusiones = %v, se esperaba %v", got, test.excludes)
+			}
+		})
+	}
+}
+
+func patternTexts(patterns []pattern) []string {
+	texts := []string{}
+	for _, pattern := range patterns {
+		texts = append(texts, pattern.text)
+	}
+	return texts
+}
+
+func TestRuleMatch(t *testing.T) {
+	rules, err := parseRules(strings.NewReader("etiqueta;incluir;excluir\n" +
+		"computo;laptop|/computadoras? portatil(es)?/;alquiler\n" +
+		"obras;construcción;\n" +
+		"mobiliario;/ESCRITORIOS? DE MELAMÍNICO/;\n" +
+		"vias;/construcción de pistas?/;\n" +
+		"portatiles;/(laptop|notebook)s?/;\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	computo, obras, mobiliario, vias, portatiles := rules.rules[0], rules.rules[1], rules.rules[2], rules.rules[3], rules.rules[4]
+
+	tests := []struct {
+		name    string
+		rule    Rule
+		texts   []string
+		pattern string
+		text    string
+		found   bool
+	}{
+		{name: "palabra", rule: computo, texts: []string{"ADQUISICIÓN DE LAPTOPS"}, pattern: "laptop", text: "ADQUISICIÓN DE LAPTOPS", found: true},
+		{name: "expresión sin tildes contra texto con tildes", rule: computo, texts: []string{"Compra de computadora portátil"}, pattern: "/computadoras? portatil(es)?/", text: "Compra de computadora portátil", found: true},
+		{name: "palabra con tilde", rule: obras, texts: []string{"CONSTRUCCION DEL LOCAL"}, pattern: "construcción", text: "CONSTRUCCION DEL LOCAL", found: true},
+		{name: "expresión con tildes", rule: vias, texts: []string{"CONSTRUCCIÓN DE PISTAS"}, pattern: "/construcción de pistas?/", text: "CONSTRUCCIÓN DE PISTAS", found: true},
+		{name: "expresión en mayúsculas con tildes", rule: mobiliario, texts: []string{"Escritorios de melamínico"}, pattern: "/ESCRITORIOS? DE MELAMÍNICO/", text: "Escritorios de melamínico", found: true},
+		{name: "expresión con alternativas", rule: portatiles, texts: []string{"Adquisición de notebooks"}, pattern: "/(laptop|notebook)s?/", text: "Adquisición de notebooks", found: true},
+		{name: "exclusión", rule: computo, texts: []string{"Alquiler de laptops"}, found: false},
+		{name: "exclusión solo en su texto", rule: computo, texts: []string{"Alquiler de laptops", "Laptops para docentes"}, pattern: "laptop", text: "Laptops para docentes", found: true},
+		{name: "sin coincidencia", rule: computo, texts: []string{"", "Servicio de limpieza"}, found: false},
+	}
+
+	for _, test := range tests {
+		match, found := test.rule.match(test.texts)
+		if found != test.found || match.Pattern != test.pattern || match.Text != test.text {
+			t.Errorf("%s: match() = %+v, %t", test.name, match, found)
+		}
+	}
+}
+
+func TestRulesTag(t *testing.T) {
+	rules, err := parseRules(strings.NewReader("etiqueta;incluir;excluir\nti;laptop;\ncomputo;laptop|impresora;\nti;impresora;\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := record.Record{ObjectType: "Bien", Items: []record.Item{{Description: "Laptops"}, {Description: "Impresoras"}}}
+	rules.Tag(&data)
+	if want := []string{"computo", "ti"}; !reflect.DeepEqual(data.Tags, want) {
+		t.Errorf("Tag() = %v, se esperaba %v", data.Tags, want)
+	}
+
+	var none *Rules
+	none.Tag(&data)
+	if len(data.Tags) != 2 {
+		t.Error("Tag() sin reglas cambió las etiquetas")
+	}
+}
+
+func TestDigestWriter(t *testing.T) {
+	rules, err := parseRules(strings.NewReader("etiqueta;incluir;excluir\ncomputo;laptop;\nobras;construccion;\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := &strings.Builder{}
+	writer := NewDigestWriter(out, rules)
+	processes := []record.Record{
+		{Nomenclature: "AS-1", Entity: "MUNICIPALIDAD A", ObjectType: "Bien", Value: "1,000.00", Currency: "Soles", Permalink: "https://example.com/1", Items: []record.Item{{Description: "Laptops"}}},
+		{Nomenclature: "AS-2", Entity: "MUNICIPALIDAD B", ObjectType: "Obra", Items: []record.Item{{Description: "Construcción de aulas"}}},
+		{Nomenclature: "AS-3", Entity: "MUNICIPALIDAD C", ObjectType: "Servicio", Items: []record.Item{{Description: "Limpieza"}}},
+	}
+	for _, data := range processes {
+		if err := writer.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	digest := out.String()
+	for _, line := range []string{
+		"2 procesos con coincidencias",
+		"## computo (1)",
+		"- AS-1 | MUNICIPALIDAD A | Bien | 1,000.00 Soles\n  \"Laptops\" (laptop)\n  https://example.com/1\n",
+		"## obras (1)",
+		"- AS-2 | MUNICIPALIDAD B | Obra |  \n  \"Construcción de aulas\" (construccion)\n  sin enlace\n",
+	} {
+		if !strings.Contains(digest, line) {
+			t.Errorf("el resumen no incluye %q:\n%s", line, digest)
+		}
+	}
+	if strings.Contains(digest, "AS-3") {
+		t.Errorf("el resumen incluye un proceso sin coincidencias:\n%s", digest)
+	}
+	if strings.Index(digest, "## computo") > strings.Index(digest, "## obras") {
+		t.Errorf("las etiquetas del resumen no están ordenadas:\n%s", digest)
+	}
+}
@@ -0,0 +1,158 @@
+package alerts
+
+import (
+	"dieg0407/seace/internal/record"
+	"dieg0407/seace/internal/table"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+const (
+	RoleWinner      = "ganador"
+	RoleParticipant = "postor"
+	RoleMember      = "integrante"
+)
+
+// Competitor es una empresa de la lista de competidores a vigilar.
+type Competitor struct {
+	Name string
+	RUC  string
+
+	name string
+}
+
+// Hit es una aparición de un competidor en un proceso.
+type Hit struct {
+	Competitor  string `json:"competidor"`
+	RUC         string `json:"ruc"`
+	Role        string `json:"rol"`
+	Item        int    `json:"item"`
+	Participant string `json:"postor"`
+}
+
+// Alert reúne las apariciones de competidores en un mismo proceso.
+type Alert struct {
+	Key          string `json:"clave"`
+	Nomenclature string `json:"nomenclatura"`
+	Entity       string `json:"entidad"`
+	ObjectType   string `json:"objeto"`
+	Value        string `json:"valor"`
+	Currency     string `json:"moneda"`
+	Permalink    string `json:"enlace,omitempty"`
+	Hits         []Hit  `json:"apariciones"`
+}
+
+// Watchlist es la lista de competidores a vigilar.
+type Watchlist struct {
+	competitors []Competitor
+}
+
+// LoadWatchlist lee la lista de competidores con las columnas nombre;ruc.
+// Basta con el nombre o con el RUC.
+func LoadWatchlist(path string) (*Watchlist, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo abrir la lista de competidores:\n%w", err)
+	}
+	defer file.Close()
+
+	return parseWatchlist(file)
+}
+
+func parseWatchlist(input io.Reader) (*Watchlist, error) {
+	watchlist := &Watchlist{}
+
+	err := table.Read(input, "la lista de competidores", 2, func(line int, columns []string) error {
+		competitor := Competitor{Name: columns[0], RUC: columns[1]}
+		competitor.name = normalizeName(competitor.Name)
+		if competitor.name == "" && competitor.RUC == "" {
+			return fmt.Errorf("la línea %d de la lista de competidores no tiene nombre ni ruc", line)
+		}
+		watchlist.competitors = append(watchlist.competitors, competitor)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return watchlist, nil
+}
+
+// Match busca a los competidores entre los postores de cada item y entre los
+// integrantes de los consorcios. Devuelve false si no aparece ninguno.
+func (w *Watchlist) Match(data record.Record) (Alert, bool) {
+	alert := Alert{
+		Key:          data.Key,
+		Nomenclature: data.Nomenclature,
+		Entity:       data.Entity,
+		ObjectType:   data.ObjectType,
+		Value:        data.Value,
+		Currency:     data.Currency,
+		Permalink:    data.Permalink,
+		Hits:         []Hit{},
+	}
+	if w == nil {
+		return alert, false
+	}
+
+	for _, item := range data.Items {
+		winnerIndex := item.WinnerIndex()
+		for i, participant := range item.Participants {
+			role := RoleParticipant
+			if i == winnerIndex {
+				role = RoleWinner
+			}
+			if competitor, ok := w.find(participant.Name, participant.RUC); ok {
+				alert.Hits = append(alert.Hits, Hit{Competitor: competitor.Name, RUC: competitor.RUC, Role: role, Item: item.Number, Participant: participant.Name})
+			}
+
+			for _, member := range participant.Members {
+				if competitor, ok := w.find(member.Name, member.RUC); ok {
+					alert.Hits = append(alert.Hits, Hit{Competitor: competitor.Name, RUC: competitor.RUC, Role: RoleMember, Item: item.Number, Participant: participant.Name})
+				}
+			}
+		}
+	}
+
+	return alert, len(alert.Hits) > 0
+}
+
+// find compara primero el RUC y después el nombre. El nombre del competidor
+// tiene que aparecer como palabras completas dentro del nombre del postor,
+// sin importar tildes, mayúsculas ni puntuación.
+func (w *Watchlist) find(name string, ruc string) (Competitor, bool) {
+	ruc = strings.TrimSpace(ruc)
+	for _, competitor := range w.competitors {
+		if competitor.RUC != "" && competitor.RUC == ruc {
+			return competitor, true
+		}
+	}
+
+	normalized := " " + normalizeName(name) + " "
+	for _, competitor := range w.competitors {
+		if competitor.name != "" && strings.Contains(normalized, " "+competitor.name+" ") {
+			return competitor, true
+		}
+	}
+
+	return Competitor{}, false
+}
+
+// normalizeName lleva un nombre a minúsculas sin tildes, quita los puntos
+// para que "S.A.C." y "SAC" coincidan y cambia el resto de signos por
+// espacios.
+func normalizeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r == '.':
+			return -1
+		case unicode.IsLetter(r), unicode.IsDigit(r):
+			return r
+		}
+		return ' '
+	}, name)
+	return record.Normalize(name)
+}
@@ -0,0 +1,100 @@
+package alerts
+
+import (
+	"dieg0407/seace/internal/record"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Constructora Ñandú S.A.C.", want: "constructora nandu sac"},
+		{name: "  CONSTRUCTORA   ÑANDÚ SAC ", want: "constructora nandu sac"},
+		{name: "Perú-Ingenieros E.I.R.L.", want: "peru ingenieros eirl"},
+		{name: "CONSORCIO SUR (2024)", want: "consorcio sur 2024"},
+		{name: "...", want: ""},
+	}
+
+	for _, test := range tests {
+		if got := normalizeName(test.name); got != test.want {
+			t.Errorf("normalizeName(%q) = %q, se esperaba %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestWatchlistFind(t *testing.T) {
+	watchlist, err := parseWatchlist(strings.NewReader("nombre;ruc\nConstructora Ñandú S.A.C.;\n;20100000001\nInca;\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		ruc   string
+		want  string
+		found bool
+	}{
+		{name: "CONSTRUCTORA NANDU SAC", want: "Constructora Ñandú S.A.C.", found: true},
+		{name: "constructora ñandú s.a.c.", want: "Constructora Ñandú S.A.C.", found: true},
+		{name: "CONSORCIO SUR - CONSTRUCTORA ÑANDU S.A.C.", want: "Constructora Ñandú S.A.C.", found: true},
+		{name: "OTRA EMPRESA SAC", ruc: " 20100000001 ", want: "", found: true},
+		{name: "INCA SERVICIOS SRL", want: "Inca", found: true},
+		{name: "INCASUR SRL", found: false},
+		{name: "CONSTRUCTORA NANDU", found: false},
+		{name: "OTRA EMPRESA SAC", ruc: "20100000002", found: false},
+	}
+
+	for _, test := range tests {
+		competitor, found := watchlist.find(test.name, test.ruc)
+		if found != test.found || competitor.Name != test.want {
+			t.Errorf("find(%q, %q) = %q, %t, se esperaba %q, %t", test.name, test.ruc, competitor.Name, found, test.want, test.found)
+		}
+	}
+}
+
+func TestParseWatchlistRejectsEmptyLine(t *testing.T) {
+	if _, err := parseWatchlist(strings.NewReader("nombre;ruc\n.;\n")); err == nil {
+		t.Error("parseWatchlist() no devolvió error con una línea sin nombre ni ruc")
+	}
+}
+
+func TestWatchlistMatch(t *testing.T) {
+	watchlist, err := parseWatchlist(strings.NewReader("nombre;ruc\nEmpresa Norte SAC;\n;20100000001\nConsorcio Sur;\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := record.Record{
+		Key:          "seace:1",
+		Nomenclature: "AS-SM-1-2024-MPL-1",
+		Items: []record.Item{{
+			Number: 1,
+			Status: "Adjudicado",
+			Participants: []record.Participant{
+				{Name: "CONSORCIO SUR", IsAwarded: record.FlagYes, Members: []record.Member{{Name: "INTEGRANTE SAC", RUC: "20100000001"}}},
+				{Name: "EMPRESA NORTE S.A.C."},
+			},
+		}},
+	}
+
+	alert, ok := watchlist.Match(data)
+	if !ok {
+		t.Fatal("Match() no encontró a los competidores")
+	}
+	want := []Hit{
+		{Competitor: "Consorcio Sur", Role: RoleWinner, Item: 1, Participant: "CONSORCIO SUR"},
+		{Competitor: "", RUC: "20100000001", Role: RoleMember, Item: 1, Participant: "CONSORCIO SUR"},
+		{Competitor: "Empresa Norte SAC", Role: RoleParticipant, Item: 1, Participant: "EMPRESA NORTE S.A.C."},
+	}
+	if !reflect.DeepEqual(alert.Hits, want) {
+		t.Errorf("Match() = %+v, se esperaba %+v", alert.Hits, want)
+	}
+
+	if _, ok := (*Watchlist)(nil).Match(data); ok {
+		t.Error("Match() sin lista de competidores encontró apariciones")
+	}
+}
@@ -0,0 +1,126 @@
+package backfill
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+const (
+	StatusCompleted = "completado"
+	StatusFailed    = "fallido"
+
+	dateLayout = "2006-01-02"
+)
+
+// DayState es el resultado de la última ejecución de un día.
+type DayState struct {
+	Status   string    `json:"estado"`
+	Attempts int       `json:"intentos"`
+	Updated  time.Time `json:"actualizado"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// State guarda en disco el avance de un backfill para poder retomarlo.
+type State struct {
+	path string
+	Days map[string]DayState `json:"dias"`
+}
+
+func LoadState(path string) (*State, error) {
+	state := &State{path: path, Days: map[string]DayState{}}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer el estado del backfill:\n%w", err)
+	}
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, fmt.Errorf("el estado del backfill está dañado:\n%w", err)
+	}
+
+	return state, nil
+}
+
+func (s *State) Completed(date time.Time) bool {
+	return s.Days[date.Format(dateLayout)].Status == StatusCompleted
+}
+
+func (s *State) record(date time.Time, err error) error {
+	key := date.Format(dateLayout)
+	day := s.Days[key]
+	day.Attempts++
+	day.Updated = time.Now()
+	day.Status = StatusCompleted
+	day.Error = ""
+	if err != nil {
+		day.Status = StatusFailed
+		day.Error = err.Error()
+	}
+	s.Days[key] = day
+
+	return s.save()
+}
+
+func (s *State) save() error {
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	temporary := s.path + ".tmp"
+	if err := os.WriteFile(temporary, content, 0644); err != nil {
+		return fmt.Errorf("no se pudo guardar el estado del backfill:\n%w", err)
+	}
+	return os.Rename(temporary, s.path)
+}
+
+// Run procesa cada día del rango [from, to], saltando los que ya se
+// completaron, y al final reintenta los que fallaron.
+func Run(from time.Time, to time.Time, state *State, retries int, scrape func(time.Time) error, logger *log.Logger) error {
+	if to.Before(from) {
+		return fmt.Errorf("la fecha final %s es anterior a la inicial %s", to.Format(dateLayout), from.Format(dateLayout))
+	}
+
+	pending := []time.Time{}
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		if state.Completed(date) {
+			logger.Printf("El día %s ya fue completado, se omite\n", date.Format(dateLayout))
+			continue
+		}
+		pending = append(pending, date)
+	}
+
+	for attempt := 0; attempt <= retries && len(pending) > 0; attempt++ {
+		if attempt > 0 {
+			logger.Printf("Reintentando %d días fallidos (intento %d de %d)\n", len(pending), attempt, retries)
+		}
+
+		failed := []time.Time{}
+		for _, date := range pending {
+			logger.Printf("Procesando el día %s\n", date.Format(dateLayout))
+
+			err := scrape(date)
+			if err != nil {
+				logger.Printf("Error al procesar el día %s:\n%v", date.Format(dateLayout), err)
+				failed = append(failed, date)
+			}
+			if err := state.record(date, err); err != nil {
+				return err
+			}
+		}
+		pending = failed
+	}
+
+	if len(pending) > 0 {
+		return fmt.Errorf("%d días no se pudieron procesar, revisa el estado del backfill", len(pending))
+	}
+
+	logger.Println("Backfill finalizado exitosamente")
+	return nil
+}
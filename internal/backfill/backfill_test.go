@@ -0,0 +1,156 @@
+package backfill
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var discard = log.New(io.Discard, "", 0)
+
+func day(value string) time.Time {
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		panic(err)
+	}
+	return date
+}
+
+// scraper registra los días procesados y falla los días indicados tantas
+// veces como se pida.
+type scraper struct {
+	days     []string
+	failures map[string]int
+}
+
+func (s *scraper) scrape(date time.Time) error {
+	key := date.Format(dateLayout)
+	s.days = append(s.days, key)
+	if s.failures[key] > 0 {
+		s.failures[key]--
+		return errors.New("portal caído")
+	}
+	return nil
+}
+
+func TestRunSkipsCompletedDays(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "estado.json")
+	state, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := state.record(day("2024-11-02"), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &scraper{}
+	if err := Run(day("2024-11-01"), day("2024-11-03"), state, 0, fake.scrape, discard); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"2024-11-01", "2024-11-03"}
+	if !reflect.DeepEqual(fake.days, want) {
+		t.Errorf("se procesaron los días %v, se esperaba %v", fake.days, want)
+	}
+}
+
+func TestRunRetriesFailedDaysAtTheEnd(t *testing.T) {
+	state, err := LoadState(filepath.Join(t.TempDir(), "estado.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &scraper{failures: map[string]int{"2024-11-01": 1}}
+	if err := Run(day("2024-11-01"), day("2024-11-02"), state, 1, fake.scrape, discard); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"2024-11-01", "2024-11-02", "2024-11-01"}
+	if !reflect.DeepEqual(fake.days, want) {
+		t.Errorf("se procesaron los días %v, se esperaba %v", fake.days, want)
+	}
+	if got := state.Days["2024-11-01"]; got.Status != StatusCompleted || got.Attempts != 2 || got.Error != "" {
+		t.Errorf("estado del día reintentado = %+v", got)
+	}
+}
+
+func TestRunFailsWhenRetriesRunOut(t *testing.T) {
+	state, err := LoadState(filepath.Join(t.TempDir(), "estado.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &scraper{failures: map[string]int{"2024-11-01": 3}}
+	if err := Run(day("2024-11-01"), day("2024-11-01"), state, 1, fake.scrape, discard); err == nil {
+		t.Fatal("Run() no devolvió error con un día que siempre falla")
+	}
+
+	got := state.Days["2024-11-01"]
+	if got.Status != StatusFailed || got.Attempts != 2 || got.Error != "portal caído" {
+		t.Errorf("estado del día fallido = %+v", got)
+	}
+}
+
+func TestRunRejectsInvertedRange(t *testing.T) {
+	state, err := LoadState(filepath.Join(t.TempDir(), "estado.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &scraper{}
+	if err := Run(day("2024-11-02"), day("2024-11-01"), state, 0, fake.scrape, discard); err == nil {
+		t.Fatal("Run() no devolvió error con la fecha final anterior a la inicial")
+	}
+	if len(fake.days) > 0 {
+		t.Errorf("se procesaron los días %v con un rango inválido", fake.days)
+	}
+}
+
+func TestStatePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "estado.json")
+	state, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &scraper{failures: map[string]int{"2024-11-02": 1}}
+	if err := Run(day("2024-11-01"), day("2024-11-02"), state, 0, fake.scrape, discard); err == nil {
+		t.Fatal("Run() no devolvió error con un día fallido")
+	}
+
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Completed(day("2024-11-01")) {
+		t.Error("el día completado no quedó guardado")
+	}
+	if loaded.Completed(day("2024-11-02")) || loaded.Days["2024-11-02"].Status != StatusFailed {
+		t.Errorf("el día fallido quedó como %+v", loaded.Days["2024-11-02"])
+	}
+
+	// Al retomar solo se procesa el día que falló
+	fake = &scraper{}
+	if err := Run(day("2024-11-01"), day("2024-11-02"), loaded, 0, fake.scrape, discard); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"2024-11-02"}; !reflect.DeepEqual(fake.days, want) {
+		t.Errorf("al retomar se procesaron los días %v, se esperaba %v", fake.days, want)
+	}
+}
+
+func TestLoadStateRejectsDamagedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "estado.json")
+	if err := os.WriteFile(path, []byte("{dias"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadState(path); err == nil {
+		t.Error("LoadState() no devolvió error con un estado dañado")
+	}
+}
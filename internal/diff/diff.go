@@ -0,0 +1,223 @@
+package diff
+
+import (
+	"dieg0407/seace/internal/record"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	KindAdded    = "agregado"
+	KindRemoved  = "eliminado"
+	KindModified = "modificado"
+)
+
+// FieldChange es un campo que cambió entre dos versiones de un proceso. Item
+// es cero para los campos del proceso.
+type FieldChange struct {
+	Field  string `json:"campo"`
+	Item   int    `json:"item,omitempty"`
+	Before string `json:"antes"`
+	After  string `json:"despues"`
+}
+
+// Change es un proceso que se agregó, se eliminó o cambió entre dos salidas.
+type Change struct {
+	Kind         string        `json:"tipo"`
+	Key          string        `json:"clave"`
+	Nomenclature string        `json:"nomenclatura"`
+	Fields       []FieldChange `json:"campos,omitempty"`
+}
+
+// Report es el resultado de comparar dos salidas.
+type Report struct {
+	Changes   []Change `json:"cambios"`
+	Unchanged int      `json:"sin_cambios"`
+}
+
+// Compare empareja los procesos por su clave estable y devuelve los que se
+// agregaron, se eliminaron o cambiaron, ordenados por clave.
+func Compare(before []record.Record, after []record.Record) Report {
+	previous := index(before)
+	current := index(after)
+
+	keys := []string{}
+	for key := range previous {
+		keys = append(keys, key)
+	}
+	for key := range current {
+		if _, ok := previous[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	report := Report{Changes: []Change{}}
+	for _, key := range keys {
+		old, hadOld := previous[key]
+		updated, hasNew := current[key]
+
+		switch {
+		case !hadOld:
+			report.Changes = append(report.Changes, Change{Kind: KindAdded, Key: key, Nomenclature: updated.Nomenclature})
+		case !hasNew:
+			report.Changes = append(report.Changes, Change{Kind: KindRemoved, Key: key, Nomenclature: old.Nomenclature})
+		default:
+			fields := Records(old, updated)
+			if len(fields) == 0 {
+				report.Unchanged++
+				continue
+			}
+			report.Changes = append(report.Changes, Change{Kind: KindModified, Key: key, Nomenclature: updated.Nomenclature, Fields: fields})
+		}
+	}
+
+	return report
+}
+
+// Records compara dos versiones del mismo proceso campo por campo: los datos
+// del proceso, las fechas del cronograma y, por cada item, su estado, su
+// ganador y el monto adjudicado.
+func Records(before record.Record, after record.Record) []FieldChange {
+	changes := []FieldChange{}
+	compare := func(field string, item int, old string, updated string) {
+		if strings.TrimSpace(old) != strings.TrimSpace(updated) {
+			changes = append(changes, FieldChange{Field: field, Item: item, Before: old, After: updated})
+		}
+	}
+
+	compare("entidad", 0, before.Entity, after.Entity)
+	compare("objeto", 0, before.ObjectType, after.ObjectType)
+	compare("valor", 0, before.Value, after.Value)
+	compare("moneda", 0, before.Currency, after.Currency)
+
+	// Las salidas CSV no tienen cronograma
+	if len(before.Schedule) > 0 && len(after.Schedule) > 0 {
+		oldStages := stages(before)
+		newStages := stages(after)
+		for _, name := range stageNames(before, after) {
+			compare("cronograma "+name+" inicio", 0, oldStages[name].StartRaw, newStages[name].StartRaw)
+			compare("cronograma "+name+" fin", 0, oldStages[name].EndRaw, newStages[name].EndRaw)
+		}
+	}
+
+	oldItems := items(before)
+	newItems := items(after)
+	numbers := []int{}
+	for number := range oldItems {
+		numbers = append(numbers, number)
+	}
+	for number := range newItems {
+		if _, ok := oldItems[number]; !ok {
+			numbers = append(numbers, number)
+		}
+	}
+	sort.Ints(numbers)
+
+	for _, number := range numbers {
+		old, hadOld := oldItems[number]
+		updated, hasNew := newItems[number]
+		switch {
+		case !hadOld:
+			compare("item", number, "", updated.Description)
+			continue
+		case !hasNew:
+			compare("item", number, old.Description, "")
+			continue
+		}
+
+		oldWinner, _ := old.Winner()
+		newWinner, _ := updated.Winner()
+		compare("estado", number, old.State, updated.State)
+		compare("estado_original", number, old.Status, updated.Status)
+		compare("ganador", number, oldWinner.Name, newWinner.Name)
+		compare("ruc_ganador", number, oldWinner.RUC, newWinner.RUC)
+		compare("monto_adjudicado", number, old.AwardedAmount, updated.AwardedAmount)
+	}
+
+	return changes
+}
+
+func index(records []record.Record) map[string]record.Record {
+	result := map[string]record.Record{}
+	for _, data := range records {
+		key := data.Key
+		if key == "" {
+			key = data.StableKey()
+		}
+		result[key] = data
+	}
+	return result
+}
+
+func stages(data record.Record) map[string]record.Stage {
+	result := map[string]record.Stage{}
+	for _, stage := range data.Schedule {
+		result[stage.Name] = stage
+	}
+	return result
+}
+
+// stageNames devuelve las etapas de ambas versiones en el orden del
+// cronograma, con las que se eliminaron al final.
+func stageNames(before record.Record, after record.Record) []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, stage := range append(append([]record.Stage{}, after.Schedule...), before.Schedule...) {
+		if !seen[stage.Name] {
+			seen[stage.Name] = true
+			names = append(names, stage.Name)
+		}
+	}
+	return names
+}
+
+func items(data record.Record) map[int]record.Item {
+	result := map[int]record.Item{}
+	for _, item := range data.Items {
+		result[item.Number] = item
+	}
+	return result
+}
+
+func (f FieldChange) name() string {
+	if f.Item > 0 {
+		return fmt.Sprintf("item %d %s", f.Item, f.Field)
+	}
+	return f.Field
+}
+
+func (r Report) WriteJSON(out io.Writer) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteText escribe el reporte para leerlo en la terminal, un proceso por
+// bloque y un campo por línea.
+func (r Report) WriteText(out io.Writer) error {
+	counts := map[string]int{}
+	for _, change := range r.Changes {
+		counts[change.Kind]++
+	}
+	if _, err := fmt.Fprintf(out, "%d agregados, %d eliminados, %d modificados, %d sin cambios\n",
+		counts[KindAdded], counts[KindRemoved], counts[KindModified], r.Unchanged); err != nil {
+		return err
+	}
+
+	for _, change := range r.Changes {
+		if _, err := fmt.Fprintf(out, "\n[%s] %s (%s)\n", change.Kind, change.Nomenclature, change.Key); err != nil {
+			return err
+		}
+		for _, field := range change.Fields {
+			if _, err := fmt.Fprintf(out, "  %s: %q -> %q\n", field.name(), field.Before, field.After); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package diff
+
+import (
+	"dieg0407/seace/internal/record"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func awardedItem(number int, winner string) record.Item {
+	return record.Item{
+		Number:       number,
+		Description:  "Item " + winner,
+		Status:       "Adjudicado",
+		State:        record.StateAwarded,
+		Participants: []record.Participant{{Name: winner, Awarded: "Ganador", IsAwarded: record.FlagYes}},
+	}
+}
+
+func TestRecords(t *testing.T) {
+	base := record.Record{
+		Key:      "seace:1",
+		Entity:   "MUNICIPALIDAD DE LIMA",
+		Value:    "1,000.00",
+		Currency: "Soles",
+		Schedule: []record.Stage{{Name: "Convocatoria", StartRaw: "01/03/2024"}},
+		Items:    []record.Item{{Number: 1, Description: "Laptops", Status: "Convocado", State: record.StateInProgress}},
+	}
+
+	tests := []struct {
+		name   string
+		change func(*record.Record)
+		want   []FieldChange
+	}{
+		{
+			name:   "sin cambios",
+			change: func(r *record.Record) { r.ID = 9 },
+			want:   []FieldChange{},
+		},
+		{
+			name:   "espacios alrededor",
+			change: func(r *record.Record) { r.Entity = " MUNICIPALIDAD DE LIMA " },
+			want:   []FieldChange{},
+		},
+		{
+			name:   "valor",
+			change: func(r *record.Record) { r.Value = "1,200.00" },
+			want:   []FieldChange{{Field: "valor", Before: "1,000.00", After: "1,200.00"}},
+		},
+		{
+			name: "cronograma",
+			change: func(r *record.Record) {
+				r.Schedule = []record.Stage{{Name: "Convocatoria", StartRaw: "04/03/2024"}}
+			},
+			want: []FieldChange{{Field: "cronograma Convocatoria inicio", Before: "01/03/2024", After: "04/03/2024"}},
+		},
+		{
+			name:   "cronograma ausente en la salida csv",
+			change: func(r *record.Record) { r.Schedule = nil },
+			want:   []FieldChange{},
+		},
+		{
+			name:   "adjudicado",
+			change: func(r *record.Record) { r.Items = []record.Item{awardedItem(1, "ACME SAC")} },
+			want: []FieldChange{
+				{Field: "estado", Item: 1, Before: record.StateInProgress, After: record.StateAwarded},
+				{Field: "estado_original", Item: 1, Before: "Convocado", After: "Adjudicado"},
+				{Field: "ganador", Item: 1, Before: "", After: "ACME SAC"},
+			},
+		},
+		{
+			name: "item nuevo",
+			change: func(r *record.Record) {
+				r.Items = append(append([]record.Item{}, r.Items...), record.Item{Number: 2, Description: "Impresoras"})
+			},
+			want: []FieldChange{{Field: "item", Item: 2, Before: "", After: "Impresoras"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			after := base
+			test.change(&after)
+			if got := Records(base, after); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Records() = %+v, se esperaba %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	before := []record.Record{
+		{Key: "nomenclatura:as-1", Nomenclature: "AS-1", Value: "100"},
+		{Key: "nomenclatura:as-2", Nomenclature: "AS-2", Value: "200"},
+		{Nomenclature: "AS-3", Value: "300"},
+	}
+	after := []record.Record{
+		{Key: "nomenclatura:as-1", Nomenclature: "AS-1", Value: "100"},
+		{Key: "nomenclatura:as-3", Nomenclature: "AS-3", Value: "350"},
+		{Nomenclature: "AS-4", Value: "400"},
+	}
+
+	report := Compare(before, after)
+	if report.Unchanged != 1 {
+		t.Errorf("Unchanged = %d, se esperaba 1", report.Unchanged)
+	}
+
+	want := []Change{
+		{Kind: KindRemoved, Key: "nomenclatura:as-2", Nomenclature: "AS-2"},
+		{Kind: KindModified, Key: "nomenclatura:as-3", Nomenclature: "AS-3", Fields: []FieldChange{{Field: "valor", Before: "300", After: "350"}}},
+		{Kind: KindAdded, Key: "nomenclatura:as-4", Nomenclature: "AS-4"},
+	}
+	if !reflect.DeepEqual(report.Changes, want) {
+		t.Errorf("Changes = %+v, se esperaba %+v", report.Changes, want)
+	}
+}
+
+func TestCompareCSVWithoutItemColumn(t *testing.T) {
+	header := "Identificador;Entidad;Nomenclarura;Objecto;Descripción;Valor;Moneda;Ganador;Es MYPE;Es Selva\n"
+	before, err := record.ReadCSV(strings.NewReader(header +
+		"1;MUNICIPALIDAD;AS-1;Bien;Laptops;1,000.00;Soles;EMPRESA A SAC;si;no\n" +
+		"1;MUNICIPALIDAD;AS-1;Bien;Impresoras;1,000.00;Soles;EMPRESA B SAC;si;no\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := record.ReadCSV(strings.NewReader(header +
+		"1;MUNICIPALIDAD;AS-1;Bien;Laptops;1,000.00;Soles;EMPRESA C SAC;si;no\n" +
+		"1;MUNICIPALIDAD;AS-1;Bien;Impresoras;1,000.00;Soles;EMPRESA B SAC;si;no\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := Compare(before, after)
+	want := []Change{{
+		Kind:         KindModified,
+		Key:          "nomenclatura:as-1",
+		Nomenclature: "AS-1",
+		Fields:       []FieldChange{{Field: "ganador", Item: 1, Before: "EMPRESA A SAC", After: "EMPRESA C SAC"}},
+	}}
+	if !reflect.DeepEqual(report.Changes, want) {
+		t.Errorf("Changes = %+v, se esperaba %+v", report.Changes, want)
+	}
+}
@@ -0,0 +1,102 @@
+package diff
+
+import (
+	"dieg0407/seace/internal/record"
+	"dieg0407/seace/internal/store"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Version es una versión guardada de un proceso con los campos que cambiaron
+// respecto a la anterior. La primera versión no tiene cambios. LastSeen es la
+// última vez que se vio el proceso con este contenido.
+type Version struct {
+	Seen     time.Time     `json:"visto"`
+	LastSeen time.Time     `json:"visto_hasta"`
+	Hash     string        `json:"hash"`
+	Changes  []FieldChange `json:"cambios"`
+	record   record.Record
+}
+
+// History es la línea de tiempo de un proceso.
+type History struct {
+	Nomenclature string    `json:"nomenclatura"`
+	Keys         []string  `json:"claves"`
+	Versions     []Version `json:"versiones"`
+}
+
+// Timeline compara cada versión del proceso con la anterior. Las entradas
+// que solo confirman una versión extienden hasta cuándo se vio.
+func Timeline(entries []store.Entry) History {
+	history := History{Keys: []string{}, Versions: []Version{}}
+	keys := map[string]bool{}
+
+	for _, entry := range entries {
+		if entry.Confirmation() {
+			if last := len(history.Versions) - 1; last >= 0 {
+				history.Versions[last].LastSeen = entry.Seen
+			}
+			continue
+		}
+
+		version := Version{Seen: entry.Seen, LastSeen: entry.Seen, Hash: entry.Hash, Changes: []FieldChange{}, record: *entry.Record}
+		if last := len(history.Versions) - 1; last >= 0 {
+			version.Changes = Records(history.Versions[last].record, *entry.Record)
+		}
+		history.Versions = append(history.Versions, version)
+		history.Nomenclature = entry.Record.Nomenclature
+
+		if !keys[entry.Key] {
+			keys[entry.Key] = true
+			history.Keys = append(history.Keys, entry.Key)
+		}
+	}
+
+	return history
+}
+
+func (h History) WriteJSON(out io.Writer) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(h)
+}
+
+// WriteText escribe una línea por versión y debajo los campos que cambiaron.
+// La primera versión muestra el estado de cada item al verse por primera vez.
+func (h History) WriteText(out io.Writer) error {
+	if _, err := fmt.Fprintf(out, "%s, %d versiones\n", h.Nomenclature, len(h.Versions)); err != nil {
+		return err
+	}
+
+	for i, version := range h.Versions {
+		seen := version.Seen.Local().Format("2006-01-02 15:04")
+		if version.LastSeen.After(version.Seen) {
+			seen += ", vista hasta " + version.LastSeen.Local().Format("2006-01-02 15:04")
+		}
+		if i == 0 {
+			if _, err := fmt.Fprintf(out, "\n%s  primera versión\n", seen); err != nil {
+				return err
+			}
+			for _, item := range version.record.Items {
+				winner, _ := item.Winner()
+				if _, err := fmt.Fprintf(out, "  item %d estado: %q, ganador: %q\n", item.Number, item.State, winner.Name); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if _, err := fmt.Fprintf(out, "\n%s  %d cambios\n", seen, len(version.Changes)); err != nil {
+			return err
+		}
+		for _, field := range version.Changes {
+			if _, err := fmt.Fprintf(out, "  %s: %q -> %q\n", field.name(), field.Before, field.After); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package diff
+
+import (
+	"dieg0407/seace/internal/record"
+	"dieg0407/seace/internal/store"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func version(key string, seen string, items ...record.Item) store.Entry {
+	parsed, err := time.Parse(time.RFC3339, seen)
+	if err != nil {
+		panic(err)
+	}
+	data := record.Record{Key: key, Nomenclature: "AS-SM-1-2024-MPL-1", Value: "1,000.00", Items: items}
+	return store.Entry{Key: key, Hash: seen, Seen: parsed, Record: &data}
+}
+
+func confirmation(key string, seen string) store.Entry {
+	parsed, err := time.Parse(time.RFC3339, seen)
+	if err != nil {
+		panic(err)
+	}
+	return store.Entry{Key: key, Hash: seen, Seen: parsed}
+}
+
+func TestTimeline(t *testing.T) {
+	pending := record.Item{Number: 1, Description: "Laptops", Status: "Convocado", State: record.StateInProgress}
+	awarded := awardedItem(1, "EMPRESA SAC")
+	awarded.Description = "Laptops"
+	awarded.AwardedAmount = "950.00"
+
+	entries := []store.Entry{
+		version("nomenclatura:as-sm-1-2024-mpl-1", "2024-03-01T10:00:00Z", pending),
+		version("nomenclatura:as-sm-1-2024-mpl-1", "2024-03-05T10:00:00Z", pending),
+		confirmation("nomenclatura:as-sm-1-2024-mpl-1", "2024-03-07T10:00:00Z"),
+		version("nomenclatura:as-sm-1-2024-mpl-1", "2024-03-09T10:00:00Z", awarded),
+	}
+	entries[1].Record.Value = "1,200.00"
+
+	history := Timeline(entries)
+
+	if history.Nomenclature != "AS-SM-1-2024-MPL-1" {
+		t.Errorf("Nomenclature = %q", history.Nomenclature)
+	}
+	if want := []string{"nomenclatura:as-sm-1-2024-mpl-1"}; !reflect.DeepEqual(history.Keys, want) {
+		t.Errorf("Keys = %v, se esperaba %v", history.Keys, want)
+	}
+	if len(history.Versions) != 3 {
+		t.Fatalf("se obtuvieron %d versiones, se esperaban 3", len(history.Versions))
+	}
+
+	want := [][]FieldChange{
+		{},
+		{{Field: "valor", Before: "1,000.00", After: "1,200.00"}},
+		{
+			{Field: "valor", Before: "1,200.00", After: "1,000.00"},
+			{Field: "estado", Item: 1, Before: record.StateInProgress, After: record.StateAwarded},
+			{Field: "estado_original", Item: 1, Before: "Convocado", After: "Adjudicado"},
+			{Field: "ganador", Item: 1, Before: "", After: "EMPRESA SAC"},
+			{Field: "monto_adjudicado", Item: 1, Before: "", After: "950.00"},
+		},
+	}
+	for i, version := range history.Versions {
+		if !reflect.DeepEqual(version.Changes, want[i]) {
+			t.Errorf("versión %d: cambios = %+v, se esperaba %+v", i+1, version.Changes, want[i])
+		}
+	}
+
+	lastSeen := []string{"2024-03-01T10:00:00Z", "2024-03-07T10:00:00Z", "2024-03-09T10:00:00Z"}
+	for i, version := range history.Versions {
+		if got := version.LastSeen.Format(time.RFC3339); got != lastSeen[i] {
+			t.Errorf("versión %d: visto hasta %s, se esperaba %s", i+1, got, lastSeen[i])
+		}
+	}
+}
+
+func TestHistoryWriteText(t *testing.T) {
+	pending := record.Item{Number: 1, Description: "Laptops", Status: "Convocado", State: record.StateInProgress}
+	history := Timeline([]store.Entry{
+		version("nomenclatura:as-sm-1-2024-mpl-1", "2024-03-01T10:00:00Z", pending),
+		version("nomenclatura:as-sm-1-2024-mpl-1", "2024-03-09T10:00:00Z", awardedItem(1, "EMPRESA SAC")),
+	})
+
+	out := &strings.Builder{}
+	if err := history.WriteText(out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{
+		"AS-SM-1-2024-MPL-1, 2 versiones",
+		`item 1 estado: "en_proceso", ganador: ""`,
+		`item 1 ganador: "" -> "EMPRESA SAC"`,
+	} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("WriteText() no incluye %q:\n%s", line, out.String())
+		}
+	}
+}
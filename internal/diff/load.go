@@ -0,0 +1,63 @@
+package diff
+
+import (
+	"bufio"
+	"dieg0407/seace/internal/record"
+	"dieg0407/seace/internal/store"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const storePrefix = `{"clave":`
+
+// Load lee una salida del scrapper: una tabla CSV, un archivo JSON con un
+// registro por línea o un almacén de procesos (--almacen), del que se toma la
+// última versión de cada proceso.
+func Load(path string) ([]record.Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo abrir %s:\n%w", path, err)
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		records, err := record.ReadCSV(file)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo leer la tabla %s:\n%w", path, err)
+		}
+		return records, nil
+	}
+
+	reader := bufio.NewReader(file)
+	first, err := reader.Peek(1)
+	if err != nil || (len(first) > 0 && first[0] != '{') {
+		records, err := record.ReadCSV(reader)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo leer la tabla %s:\n%w", path, err)
+		}
+		return records, nil
+	}
+
+	if isStore(reader) {
+		processes, err := store.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		return processes.Records(), nil
+	}
+
+	records, err := record.ReadJSON(reader)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer %s:\n%w", path, err)
+	}
+	return records, nil
+}
+
+// isStore revisa si el archivo empieza con una versión del almacén, que
+// siempre abre con su clave, en lugar de un registro.
+func isStore(reader *bufio.Reader) bool {
+	prefix, _ := reader.Peek(len(storePrefix))
+	return string(prefix) == storePrefix
+}
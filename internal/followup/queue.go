@@ -0,0 +1,124 @@
+package followup
+
+import (
+	"dieg0407/seace/internal/record"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// Pending es un proceso que todavía no llegó a un estado final y que se debe
+// volver a revisar.
+type Pending struct {
+	Key          string    `json:"clave"`
+	Nomenclature string    `json:"nomenclatura"`
+	Permalink    string    `json:"enlace,omitempty"`
+	Added        time.Time `json:"agregado"`
+	Checked      time.Time `json:"revisado"`
+	Attempts     int       `json:"intentos"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// Queue guarda en disco los procesos pendientes de revisión.
+type Queue struct {
+	path      string
+	Processes map[string]Pending `json:"procesos"`
+}
+
+func LoadQueue(path string) (*Queue, error) {
+	queue := &Queue{path: path, Processes: map[string]Pending{}}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return queue, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer la cola de seguimiento:\n%w", err)
+	}
+	if err := json.Unmarshal(content, queue); err != nil {
+		return nil, fmt.Errorf("la cola de seguimiento está dañada:\n%w", err)
+	}
+
+	return queue, nil
+}
+
+// Track agrega el proceso a la cola si todavía no está resuelto y lo saca si
+// ya lo está. Devuelve true si el proceso quedó en la cola.
+func (q *Queue) Track(data record.Record) (bool, error) {
+	if q == nil || data.Key == "" {
+		return false, nil
+	}
+
+	if data.Resolved() {
+		if _, ok := q.Processes[data.Key]; !ok {
+			return false, nil
+		}
+		delete(q.Processes, data.Key)
+		return false, q.save()
+	}
+
+	pending, ok := q.Processes[data.Key]
+	if !ok {
+		pending = Pending{Key: data.Key, Added: time.Now()}
+	}
+	pending.Nomenclature = data.Nomenclature
+	if data.Permalink != "" {
+		pending.Permalink = data.Permalink
+	}
+	q.Processes[data.Key] = pending
+
+	return true, q.save()
+}
+
+// Due devuelve los procesos que no se revisaron en el último intervalo,
+// empezando por los más antiguos.
+func (q *Queue) Due(interval time.Duration, now time.Time) []Pending {
+	due := []Pending{}
+	for _, pending := range q.Processes {
+		if pending.Checked.IsZero() || now.Sub(pending.Checked) >= interval {
+			due = append(due, pending)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].Added.Before(due[j].Added) })
+	return due
+}
+
+// Checked registra una revisión del proceso. Si el proceso sigue pendiente y
+// ya se revisó maxAttempts veces se saca de la cola y devuelve true.
+func (q *Queue) Checked(key string, err error, maxAttempts int) (bool, error) {
+	pending, ok := q.Processes[key]
+	if !ok {
+		return false, nil
+	}
+
+	pending.Attempts++
+	pending.Checked = time.Now()
+	pending.Error = ""
+	if err != nil {
+		pending.Error = err.Error()
+	}
+	q.Processes[key] = pending
+
+	abandoned := maxAttempts > 0 && pending.Attempts >= maxAttempts
+	if abandoned {
+		delete(q.Processes, key)
+	}
+
+	return abandoned, q.save()
+}
+
+func (q *Queue) save() error {
+	content, err := json.MarshalIndent(q, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	temporary := q.path + ".tmp"
+	if err := os.WriteFile(temporary, content, 0644); err != nil {
+		return fmt.Errorf("no se pudo guardar la cola de seguimiento:\n%w", err)
+	}
+	return os.Rename(temporary, q.path)
+}
@@ -0,0 +1,162 @@
+package followup
+
+import (
+	"dieg0407/seace/internal/record"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func process(key string, state string) record.Record {
+	return record.Record{
+		Key:          key,
+		Nomenclature: strings.ToUpper(strings.TrimPrefix(key, "nomenclatura:")),
+		Items:        []record.Item{{Number: 1, State: state}},
+	}
+}
+
+func newQueue(t *testing.T) (*Queue, string) {
+	path := filepath.Join(t.TempDir(), "cola.json")
+	queue, err := LoadQueue(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return queue, path
+}
+
+func TestTrack(t *testing.T) {
+	queue, path := newQueue(t)
+
+	tests := []struct {
+		name    string
+		data    record.Record
+		pending bool
+		queued  int
+	}{
+		{name: "sin clave", data: record.Record{Items: []record.Item{{Number: 1, State: record.StateInProgress}}}, pending: false, queued: 0},
+		{name: "en proceso", data: process("nomenclatura:as-sm-1", record.StateInProgress), pending: true, queued: 1},
+		{name: "otra vez en proceso", data: process("nomenclatura:as-sm-1", record.StateInProgress), pending: true, queued: 1},
+		{name: "desierto sin estar en la cola", data: process("nomenclatura:as-sm-2", record.StateDeserted), pending: false, queued: 1},
+		{name: "resuelto", data: process("nomenclatura:as-sm-1", record.StateDeserted), pending: false, queued: 0},
+	}
+
+	for _, test := range tests {
+		pending, err := queue.Track(test.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pending != test.pending || len(queue.Processes) != test.queued {
+			t.Errorf("%s: Track() = %t con %d en la cola, se esperaba %t con %d", test.name, pending, len(queue.Processes), test.pending, test.queued)
+		}
+
+		loaded, err := LoadQueue(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(loaded.Processes) != len(queue.Processes) {
+			t.Errorf("%s: la cola guardada tiene %d procesos, se esperaban %d", test.name, len(loaded.Processes), len(queue.Processes))
+		}
+	}
+}
+
+func TestTrackKeepsAddedDate(t *testing.T) {
+	queue, _ := newQueue(t)
+	if _, err := queue.Track(process("nomenclatura:as-sm-1", record.StateInProgress)); err != nil {
+		t.Fatal(err)
+	}
+	added := queue.Processes["nomenclatura:as-sm-1"].Added
+
+	data := process("nomenclatura:as-sm-1", record.StateInProgress)
+	data.Permalink = "https://example.com/ficha"
+	if _, err := queue.Track(data); err != nil {
+		t.Fatal(err)
+	}
+
+	pending := queue.Processes["nomenclatura:as-sm-1"]
+	if !pending.Added.Equal(added) || pending.Permalink != data.Permalink {
+		t.Errorf("Track() dejó %+v, se esperaba la fecha %s y el enlace %s", pending, added, data.Permalink)
+	}
+}
+
+func TestDue(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	queue := &Queue{Processes: map[string]Pending{
+		"nuevo":     {Key: "nuevo", Added: now.Add(-time.Hour)},
+		"reciente":  {Key: "reciente", Added: now.Add(-72 * time.Hour), Checked: now.Add(-24 * time.Hour)},
+		"vencido":   {Key: "vencido", Added: now.Add(-96 * time.Hour), Checked: now.Add(-48 * time.Hour)},
+		"en limite": {Key: "en limite", Added: now.Add(-50 * time.Hour), Checked: now.Add(-48 * time.Hour)},
+	}}
+
+	due := queue.Due(48*time.Hour, now)
+
+	want := []string{"vencido", "en limite", "nuevo"}
+	if len(due) != len(want) {
+		t.Fatalf("Due() = %+v, se esperaba %v", due, want)
+	}
+	for i, pending := range due {
+		if pending.Key != want[i] {
+			t.Errorf("Due()[%d] = %s, se esperaba %s", i, pending.Key, want[i])
+		}
+	}
+}
+
+func TestChecked(t *testing.T) {
+	queue, path := newQueue(t)
+	if _, err := queue.Track(process("nomenclatura:as-sm-1", record.StateInProgress)); err != nil {
+		t.Fatal(err)
+	}
+
+	abandoned, err := queue.Checked("nomenclatura:as-sm-1", errors.New("portal caído"), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending := queue.Processes["nomenclatura:as-sm-1"]
+	if abandoned || pending.Attempts != 1 || pending.Checked.IsZero() || pending.Error != "portal caído" {
+		t.Errorf("primera revisión: Checked() = %t, %+v", abandoned, pending)
+	}
+
+	abandoned, err = queue.Checked("nomenclatura:as-sm-1", nil, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !abandoned {
+		t.Error("Checked() no sacó de la cola el proceso que llegó al límite de revisiones")
+	}
+	if _, ok := queue.Processes["nomenclatura:as-sm-1"]; ok {
+		t.Error("el proceso abandonado sigue en la cola")
+	}
+
+	loaded, err := LoadQueue(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Processes) != 0 {
+		t.Errorf("la cola guardada tiene %d procesos, se esperaba vacía", len(loaded.Processes))
+	}
+}
+
+func TestCheckedWithoutLimit(t *testing.T) {
+	queue, _ := newQueue(t)
+	if _, err := queue.Track(process("nomenclatura:as-sm-1", record.StateInProgress)); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 5; i++ {
+		abandoned, err := queue.Checked("nomenclatura:as-sm-1", nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if abandoned {
+			t.Fatal("Checked() abandonó un proceso sin límite de revisiones")
+		}
+	}
+	if attempts := queue.Processes["nomenclatura:as-sm-1"].Attempts; attempts != 5 {
+		t.Errorf("Attempts = %d, se esperaba 5", attempts)
+	}
+
+	if abandoned, err := queue.Checked("nomenclatura:as-sm-9", nil, 1); abandoned || err != nil {
+		t.Errorf("Checked() de un proceso que no está en la cola = %t, %v", abandoned, err)
+	}
+}
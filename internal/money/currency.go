@@ -0,0 +1,46 @@
+package money
+
+import (
+	"dieg0407/seace/internal/text"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var currencyCodes = []struct {
+	tokens []string
+	code   string
+}{
+	{[]string{"sol", "soles", "s/", "pen"}, "PEN"},
+	{[]string{"dolar", "dolares", "us$", "usd"}, "USD"},
+	{[]string{"euro", "euros", "€", "eur"}, "EUR"},
+	{[]string{"yen", "yenes", "jpy"}, "JPY"},
+	{[]string{"libra", "libras", "gbp"}, "GBP"},
+	{[]string{"franco", "francos", "chf"}, "CHF"},
+}
+
+// separators parten el texto de la moneda en palabras. La barra y el signo de
+// dólar no están porque son parte de "S/" y "US$".
+var separators = strings.NewReplacer("(", " ", ")", " ", ",", " ", ";", " ", "-", " ", ":", " ")
+
+// CurrencyCode devuelve el código ISO 4217 de la moneda tal como la escribe
+// el portal ("Soles", "Nuevos Soles", "S/.", "Dólares Americanos"). Se
+// comparan palabras completas, así que un texto que solo contiene las letras
+// de una moneda no la reconoce.
+func CurrencyCode(currency string) (string, error) {
+	normalized := text.Normalize(currency)
+	if normalized == "" {
+		return "", fmt.Errorf("la moneda está vacía")
+	}
+
+	words := strings.Fields(separators.Replace(normalized))
+	for _, candidate := range currencyCodes {
+		for _, word := range words {
+			if slices.Contains(candidate.tokens, strings.TrimRight(word, ".")) {
+				return candidate.code, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("moneda no reconocida: %s", currency)
+}
@@ -0,0 +1,44 @@
+package money
+
+import "testing"
+
+func TestCurrencyCode(t *testing.T) {
+	tests := []struct {
+		currency string
+		want     string
+		wantErr  bool
+	}{
+		{currency: "Soles", want: "PEN"},
+		{currency: "Nuevos Soles", want: "PEN"},
+		{currency: "S/.", want: "PEN"},
+		{currency: "S/", want: "PEN"},
+		{currency: "PEN", want: "PEN"},
+		{currency: "Soles (S/)", want: "PEN"},
+		{currency: "Dólares Americanos", want: "USD"},
+		{currency: "  DÓLARES   AMERICANOS ", want: "USD"},
+		{currency: "US$", want: "USD"},
+		{currency: "  EURO ", want: "EUR"},
+		{currency: "Yenes", want: "JPY"},
+		{currency: "Libra Esterlina", want: "GBP"},
+		{currency: "Franco Suizo", want: "CHF"},
+		{currency: "", wantErr: true},
+		{currency: "Bitcoin", wantErr: true},
+		{currency: "Pendiente", wantErr: true},
+		{currency: "Consolidado", wantErr: true},
+		{currency: "Peso Colombiano", wantErr: true},
+		{currency: "Solicitado", wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := CurrencyCode(test.currency)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("CurrencyCode(%q) = %s, se esperaba un error", test.currency, got)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("CurrencyCode(%q) = %s, %v, se esperaba %s", test.currency, got, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package money
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+var amountPattern = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+
+// Decimal es un monto exacto, sin los errores de redondeo de un float.
+type Decimal struct {
+	value *big.Rat
+	scale int
+}
+
+func NewDecimal(value *big.Rat, scale int) Decimal {
+	return Decimal{value: value, scale: scale}
+}
+
+// ParseDecimal interpreta un monto como lo muestra el portal, con comas como
+// separador de miles y punto decimal ("1,234,567.89"). También acepta el
+// formato con puntos de miles y coma decimal ("1.234.567,89").
+func ParseDecimal(text string) (Decimal, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || r == '.' || r == ',' || r == '-' {
+			return r
+		}
+		return -1
+	}, text)
+	cleaned = strings.Trim(cleaned, ".,")
+	if cleaned == "" {
+		return Decimal{}, fmt.Errorf("el monto '%s' no tiene dígitos", text)
+	}
+
+	lastComma := strings.LastIndex(cleaned, ",")
+	lastDot := strings.LastIndex(cleaned, ".")
+	switch {
+	case lastComma > lastDot && (lastDot >= 0 || strings.Count(cleaned, ",") == 1 && len(cleaned)-lastComma-1 <= 2):
+		cleaned = strings.ReplaceAll(cleaned, ".", "")
+		cleaned = strings.Replace(cleaned, ",", ".", 1)
+	default:
+		cleaned = strings.ReplaceAll(cleaned, ",", "")
+	}
+
+	if !amountPattern.MatchString(cleaned) {
+		return Decimal{}, fmt.Errorf("el monto '%s' no tiene un formato válido", text)
+	}
+
+	value, ok := new(big.Rat).SetString(cleaned)
+	if !ok {
+		return Decimal{}, fmt.Errorf("el monto '%s' no tiene un formato válido", text)
+	}
+
+	scale := 0
+	if dot := strings.Index(cleaned, "."); dot >= 0 {
+		scale = len(cleaned) - dot - 1
+	}
+
+	return Decimal{value: value, scale: max(scale, 2)}, nil
+}
+
+func (d Decimal) IsZero() bool {
+	return d.value == nil
+}
+
+func (d Decimal) Rat() *big.Rat {
+	if d.value == nil {
+		return new(big.Rat)
+	}
+	return new(big.Rat).Set(d.value)
+}
+
+func (d Decimal) String() string {
+	if d.value == nil {
+		return ""
+	}
+	return d.value.FloatString(d.scale)
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Decimal) UnmarshalJSON(content []byte) error {
+	var text string
+	if err := json.Unmarshal(content, &text); err != nil {
+		return err
+	}
+	if text == "" {
+		*d = Decimal{}
+		return nil
+	}
+
+	parsed, err := ParseDecimal(text)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Mul multiplica dos montos sin perder precisión y redondea el resultado a
+// dos decimales al mostrarlo.
+func (d Decimal) Mul(other Decimal) Decimal {
+	return Decimal{value: new(big.Rat).Mul(d.Rat(), other.Rat()), scale: 2}
+}
+
+// Add suma dos montos conservando la mayor cantidad de decimales.
+func (d Decimal) Add(other Decimal) Decimal {
+	return Decimal{value: new(big.Rat).Add(d.Rat(), other.Rat()), scale: max(d.scale, other.scale, 2)}
+}
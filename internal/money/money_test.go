@@ -0,0 +1,79 @@
+package money
+
+import "testing"
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{text: "1,234,567.89", want: "1234567.89"},
+		{text: "1.234.567,89", want: "1234567.89"},
+		{text: "1234,5", want: "1234.50"},
+		{text: "1,234", want: "1234.00"},
+		{text: "S/. 2,500.00", want: "2500.00"},
+		{text: "US$ 10.125", want: "10.125"},
+		{text: "-15.5", want: "-15.50"},
+		{text: "0", want: "0.00"},
+		{text: "", wantErr: true},
+		{text: "S/.", wantErr: true},
+		{text: "1-2", wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseDecimal(test.text)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("ParseDecimal(%q) = %s, se esperaba un error", test.text, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseDecimal(%q) devolvió un error: %v", test.text, err)
+			continue
+		}
+		if got.String() != test.want {
+			t.Errorf("ParseDecimal(%q) = %s, se esperaba %s", test.text, got, test.want)
+		}
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	amount, _ := ParseDecimal("1,000.10")
+	rate, _ := ParseDecimal("3.745")
+
+	if got := amount.Mul(rate).String(); got != "3745.37" {
+		t.Errorf("Mul = %s, se esperaba 3745.37", got)
+	}
+	if got := amount.Add(rate).String(); got != "1003.845" {
+		t.Errorf("Add = %s, se esperaba 1003.845", got)
+	}
+	if got := (Decimal{}).Add(amount).String(); got != "1000.10" {
+		t.Errorf("Add desde cero = %s, se esperaba 1000.10", got)
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	amount, _ := ParseDecimal("1,234.5")
+	content, err := amount.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != `"1234.50"` {
+		t.Fatalf("MarshalJSON = %s", content)
+	}
+
+	var parsed Decimal
+	if err := parsed.UnmarshalJSON(content); err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Rat().Cmp(amount.Rat()) != 0 {
+		t.Errorf("UnmarshalJSON = %s, se esperaba %s", parsed, amount)
+	}
+
+	var empty Decimal
+	if err := empty.UnmarshalJSON([]byte(`""`)); err != nil || !empty.IsZero() {
+		t.Errorf("UnmarshalJSON de un texto vacío = %s, %v", empty, err)
+	}
+}
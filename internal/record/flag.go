@@ -0,0 +1,76 @@
+package record
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Flag es un campo sí/no de la ficha que puede no estar informado.
+type Flag int8
+
+const (
+	FlagUnknown Flag = iota
+	FlagYes
+	FlagNo
+)
+
+var flagValues = map[string]Flag{
+	"si": FlagYes, "s": FlagYes, "x": FlagYes, "yes": FlagYes, "true": FlagYes, "1": FlagYes,
+	"no": FlagNo, "n": FlagNo, "false": FlagNo, "0": FlagNo,
+	"-": FlagUnknown,
+}
+
+// ParseFlag interpreta los textos con los que el portal marca un sí o un no,
+// sin importar tildes ni mayúsculas. Un texto vacío o un "-", que en el portal
+// significa que no se informó, es desconocido; uno que no se reconoce también,
+// pero devuelve un error para poder reportarlo.
+func ParseFlag(text string) (Flag, error) {
+	normalized := Normalize(text)
+	if normalized == "" {
+		return FlagUnknown, nil
+	}
+
+	if flag, ok := flagValues[normalized]; ok {
+		return flag, nil
+	}
+	return FlagUnknown, fmt.Errorf("valor sí/no no reconocido: '%s'", text)
+}
+
+func (f Flag) String() string {
+	switch f {
+	case FlagYes:
+		return "si"
+	case FlagNo:
+		return "no"
+	}
+	return ""
+}
+
+// Bool devuelve el valor del campo y false en ok si es desconocido.
+func (f Flag) Bool() (value bool, ok bool) {
+	return f == FlagYes, f != FlagUnknown
+}
+
+func (f Flag) MarshalJSON() ([]byte, error) {
+	if value, ok := f.Bool(); ok {
+		return json.Marshal(value)
+	}
+	return []byte("null"), nil
+}
+
+func (f *Flag) UnmarshalJSON(content []byte) error {
+	var value *bool
+	if err := json.Unmarshal(content, &value); err != nil {
+		return err
+	}
+
+	switch {
+	case value == nil:
+		*f = FlagUnknown
+	case *value:
+		*f = FlagYes
+	default:
+		*f = FlagNo
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package record
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseFlag(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    Flag
+		wantErr bool
+	}{
+		{text: "SI", want: FlagYes},
+		{text: "Sí", want: FlagYes},
+		{text: " s ", want: FlagYes},
+		{text: "X", want: FlagYes},
+		{text: "NO", want: FlagNo},
+		{text: "n", want: FlagNo},
+		{text: "", want: FlagUnknown},
+		{text: "-", want: FlagUnknown},
+		{text: "quizás", want: FlagUnknown, wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseFlag(test.text)
+		if got != test.want || (err != nil) != test.wantErr {
+			t.Errorf("ParseFlag(%q) = %v, %v, se esperaba %v con error: %t", test.text, got, err, test.want, test.wantErr)
+		}
+	}
+}
+
+func TestFlagJSON(t *testing.T) {
+	tests := []struct {
+		flag Flag
+		json string
+	}{
+		{flag: FlagYes, json: "true"},
+		{flag: FlagNo, json: "false"},
+		{flag: FlagUnknown, json: "null"},
+	}
+
+	for _, test := range tests {
+		content, err := json.Marshal(test.flag)
+		if err != nil || string(content) != test.json {
+			t.Errorf("json.Marshal(%v) = %s, %v, se esperaba %s", test.flag, content, err, test.json)
+		}
+
+		var parsed Flag
+		if err := json.Unmarshal([]byte(test.json), &parsed); err != nil || parsed != test.flag {
+			t.Errorf("json.Unmarshal(%s) = %v, %v, se esperaba %v", test.json, parsed, err, test.flag)
+		}
+	}
+}
+
+func TestParseAwarded(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    Flag
+		wantErr bool
+	}{
+		{text: "Ganador", want: FlagYes},
+		{text: "ADJUDICADO", want: FlagYes},
+		{text: "Sí", want: FlagYes},
+		{text: "No", want: FlagNo},
+		{text: "", want: FlagUnknown},
+		{text: "Pendiente", want: FlagUnknown, wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseAwarded(test.text)
+		if got != test.want || (err != nil) != test.wantErr {
+			t.Errorf("ParseAwarded(%q) = %v, %v, se esperaba %v con error: %t", test.text, got, err, test.want, test.wantErr)
+		}
+	}
+}
+
+func TestNormalizeFlagsReportsAwarded(t *testing.T) {
+	participant := Participant{Name: "EMPRESA SAC", Awarded: "Pendiente"}
+	participant.NormalizeFlags()
+
+	if participant.IsAwarded != FlagUnknown || len(participant.FlagErrors) != 1 || !strings.HasPrefix(participant.FlagErrors[0], "buena pro: ") {
+		t.Errorf("NormalizeFlags() = %v, %v", participant.IsAwarded, participant.FlagErrors)
+	}
+}
@@ -0,0 +1,52 @@
+package record
+
+import (
+	"crypto/sha256"
+	"dieg0407/seace/internal/money"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// StableKey identifica al proceso entre ejecuciones por su nomenclatura
+// normalizada. El id interno de SEACE queda en ProcessID y no forma parte de
+// la clave, porque no siempre se puede leer de la ficha y el mismo proceso
+// cambiaría de clave entre ejecuciones.
+func (r Record) StableKey() string {
+	if normalized := Normalize(r.Nomenclature); normalized != "" {
+		return "nomenclatura:" + normalized
+	}
+	return ""
+}
+
+// ContentHash es el SHA-256 del contenido del registro tal como sale de la
+// ficha, así que se debe calcular antes de completarlo con las tablas de
+// entidades. No depende de la posición en los resultados, de los enlaces (que
+// pueden llevar datos de la sesión), de dónde se descargaron los documentos,
+// de las etiquetas ni de la conversión a soles, así que dos ejecuciones que
+// ven el mismo proceso sin cambios dan el mismo hash. De los documentos solo
+// cuentan el nombre, la etapa y la fecha de publicación.
+func (r Record) ContentHash() string {
+	r.ID = 0
+	r.Hash = ""
+	r.Permalink = ""
+	r.Tags = nil
+	r.AmountPEN = money.Decimal{}
+	r.ExchangeRate = money.Decimal{}
+	r.ExchangeRateDate = ""
+	r.ConversionError = ""
+	documents := make([]Document, len(r.Documents))
+	for i, document := range r.Documents {
+		document.URL = ""
+		document.File = ""
+		document.SHA256 = ""
+		documents[i] = document
+	}
+	r.Documents = documents
+
+	content, err := json.Marshal(r)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,73 @@
+package record
+
+import (
+	"dieg0407/seace/internal/money"
+	"math/big"
+	"testing"
+)
+
+func TestStableKey(t *testing.T) {
+	tests := []struct {
+		data Record
+		want string
+	}{
+		{data: Record{ProcessID: "1045678", Nomenclature: "AS-SM-1-2024"}, want: "nomenclatura:as-sm-1-2024"},
+		{data: Record{Nomenclature: "AS-SM-1-2024"}, want: "nomenclatura:as-sm-1-2024"},
+		{data: Record{Nomenclature: " AS-SM-1-2024-Munícipal "}, want: "nomenclatura:as-sm-1-2024-municipal"},
+		{data: Record{}, want: ""},
+	}
+
+	for _, test := range tests {
+		if got := test.data.StableKey(); got != test.want {
+			t.Errorf("StableKey() = %q, se esperaba %q", got, test.want)
+		}
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	base := Record{
+		Nomenclature: "AS-SM-1-2024",
+		Entity:       "MUNICIPALIDAD DE LIMA",
+		Value:        "1,000.00",
+		Currency:     "Dólares",
+		Documents:    []Document{{Name: "Bases", URL: "https://example.com/bases.pdf"}},
+	}
+	hash := base.ContentHash()
+
+	same := []struct {
+		name   string
+		change func(*Record)
+	}{
+		{name: "posición", change: func(r *Record) { r.ID = 7 }},
+		{name: "etiquetas", change: func(r *Record) { r.Tags = []string{"obras"} }},
+		{name: "descarga", change: func(r *Record) { r.Documents[0].File = "documentos/bases.pdf"; r.Documents[0].SHA256 = "abc" }},
+		{name: "enlace del documento", change: func(r *Record) { r.Documents[0].URL = "https://example.com/bases.pdf?cid=3&windowId=9" }},
+		{name: "enlace de la ficha", change: func(r *Record) { r.Permalink = "https://example.com/ficha?id=1045678" }},
+		{name: "tipo de cambio", change: func(r *Record) {
+			r.AmountPEN = money.NewDecimal(big.NewRat(3745, 1), 2)
+			r.ExchangeRate = money.NewDecimal(big.NewRat(3745, 1000), 3)
+			r.ExchangeRateDate = "2024-03-01"
+		}},
+		{name: "error de conversión", change: func(r *Record) { r.ConversionError = "sin tipo de cambio" }},
+	}
+	for _, test := range same {
+		data := base
+		data.Documents = append([]Document{}, base.Documents...)
+		test.change(&data)
+		if got := data.ContentHash(); got != hash {
+			t.Errorf("ContentHash() cambió con %s", test.name)
+		}
+	}
+
+	changed := base
+	changed.Value = "2,000.00"
+	if changed.ContentHash() == hash {
+		t.Error("ContentHash() no cambió con el valor del proceso")
+	}
+
+	renamed := base
+	renamed.Documents = []Document{{Name: "Acta de buena pro", URL: "https://example.com/bases.pdf"}}
+	if renamed.ContentHash() == hash {
+		t.Error("ContentHash() no cambió con el nombre del documento")
+	}
+}
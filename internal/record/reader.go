@@ -0,0 +1,135 @@
+package record
+
+import (
+	"bufio"
+	"dieg0407/seace/internal/money"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ReadJSON lee los registros escritos por JSONWriter, uno por línea.
+func ReadJSON(input io.Reader) ([]Record, error) {
+	records := []Record{}
+
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var data Record
+		if err := json.Unmarshal(scanner.Bytes(), &data); err != nil {
+			return nil, fmt.Errorf("la línea %d no es un registro JSON válido:\n%w", line, err)
+		}
+		records = append(records, data)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// ReadCSV reconstruye los registros de la tabla escrita por CSVWriter juntando
+// las filas de cada item. Solo se recuperan las columnas de la tabla: los
+// postores se reducen al ganador y no hay cronograma ni documentos. Las
+// columnas se buscan por su cabecera, así que también se pueden leer reportes
+// de versiones anteriores. Si la tabla no tiene la columna Item, los items de
+// cada proceso se numeran en el orden de sus filas.
+func ReadCSV(input io.Reader) ([]Record, error) {
+	reader := csv.NewReader(input)
+	reader.Comma = ';'
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return []Record{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer la cabecera:\n%w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	_, numbered := columns["Item"]
+
+	records := []Record{}
+	positions := map[string]int{}
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo leer la línea %d:\n%w", line, err)
+		}
+		get := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		data := Record{
+			Key:          get("Clave"),
+			Hash:         get("Hash"),
+			Permalink:    get("Enlace"),
+			Entity:       get("Entidad"),
+			EntityRUC:    get("RUC Entidad"),
+			Department:   get("Departamento"),
+			Province:     get("Provincia"),
+			District:     get("Distrito"),
+			Ubigeo:       get("Ubigeo"),
+			Nomenclature: get("Nomenclarura"),
+			ObjectType:   get("Objecto"),
+			Value:        get("Valor"),
+			Currency:     get("Moneda"),
+			CurrencyCode: get("Moneda ISO"),
+		}
+		data.ID, _ = strconv.Atoi(get("Identificador"))
+		data.Amount, _ = money.ParseDecimal(get("Valor Decimal"))
+		if data.Key == "" {
+			data.Key = data.StableKey()
+		}
+
+		item := Item{
+			Description:    get("Descripción"),
+			Quantity:       get("Cantidad"),
+			Unit:           get("Unidad"),
+			ReferenceValue: get("Valor Item"),
+			Status:         get("Estado Item"),
+			State:          get("Estado"),
+			AwardedAmount:  get("Monto Adjudicado"),
+			Participants:   []Participant{},
+		}
+		item.Number, _ = strconv.Atoi(get("Item"))
+		if winner := get("Ganador"); winner != "" {
+			participant := Participant{Name: winner, RUC: get("RUC Ganador"), IsAwarded: FlagYes}
+			participant.IsMYPE, _ = ParseFlag(get("Es MYPE"))
+			participant.IsSelva, _ = ParseFlag(get("Es Selva"))
+			item.Participants = append(item.Participants, participant)
+		}
+
+		position, ok := positions[data.Key]
+		if !ok {
+			position = len(records)
+			positions[data.Key] = position
+			records = append(records, data)
+		}
+		if !numbered {
+			item.Number = len(records[position].Items) + 1
+		}
+		records[position].Items = append(records[position].Items, item)
+	}
+
+	return records, nil
+}
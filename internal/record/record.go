@@ -0,0 +1,298 @@
+package record
+
+import (
+	"dieg0407/seace/internal/money"
+	"dieg0407/seace/internal/text"
+	"strings"
+	"time"
+)
+
+// Record es la información extraída de la ficha de un proceso. ID es solo la
+// posición en los resultados de la búsqueda; Key identifica al proceso entre
+// ejecuciones.
+type Record struct {
+	ID               int           `json:"identificador"`
+	Key              string        `json:"clave"`
+	ProcessID        string        `json:"id_proceso,omitempty"`
+	Hash             string        `json:"hash"`
+	Permalink        string        `json:"enlace,omitempty"`
+	Entity           string        `json:"entidad"`
+	EntityRUC        string        `json:"ruc_entidad,omitempty"`
+	Department       string        `json:"departamento,omitempty"`
+	Province         string        `json:"provincia,omitempty"`
+	District         string        `json:"distrito,omitempty"`
+	Ubigeo           string        `json:"ubigeo,omitempty"`
+	Nomenclature     string        `json:"nomenclatura"`
+	ObjectType       string        `json:"objeto"`
+	Value            string        `json:"valor"`
+	Currency         string        `json:"moneda"`
+	Amount           money.Decimal `json:"valor_decimal"`
+	CurrencyCode     string        `json:"moneda_iso"`
+	ValueError       string        `json:"error_valor,omitempty"`
+	AmountPEN        money.Decimal `json:"valor_pen"`
+	ExchangeRate     money.Decimal `json:"tipo_cambio"`
+	ExchangeRateDate string        `json:"fecha_tipo_cambio,omitempty"`
+	ConversionError  string        `json:"error_conversion,omitempty"`
+	Tags             []string      `json:"etiquetas,omitempty"`
+	Items            []Item        `json:"items"`
+	Schedule         []Stage       `json:"cronograma"`
+	Documents        []Document    `json:"documentos"`
+}
+
+// Document es un documento publicado en la ficha del proceso. File y SHA256
+// solo se llenan cuando el documento fue descargado.
+type Document struct {
+	Name         string     `json:"nombre"`
+	Stage        string     `json:"etapa"`
+	Published    *time.Time `json:"publicacion,omitempty"`
+	PublishedRaw string     `json:"publicacion_original"`
+	URL          string     `json:"url"`
+	File         string     `json:"archivo,omitempty"`
+	SHA256       string     `json:"sha256,omitempty"`
+}
+
+// Stage es una etapa del cronograma del proceso. Las fechas están en la hora
+// de Lima; si el portal no muestra una fecha el campo queda vacío.
+type Stage struct {
+	Name     string     `json:"etapa"`
+	Start    *time.Time `json:"inicio,omitempty"`
+	End      *time.Time `json:"fin,omitempty"`
+	StartRaw string     `json:"inicio_original"`
+	EndRaw   string     `json:"fin_original"`
+}
+
+// Item es cada uno de los items en los que se divide un proceso, con sus
+// propios postores y ganador.
+type Item struct {
+	Number         int           `json:"numero"`
+	Description    string        `json:"descripcion"`
+	Quantity       string        `json:"cantidad,omitempty"`
+	Unit           string        `json:"unidad,omitempty"`
+	ReferenceValue string        `json:"valor_referencial,omitempty"`
+	Status         string        `json:"estado,omitempty"`
+	State          string        `json:"estado_normalizado"`
+	AwardedAmount  string        `json:"monto_adjudicado,omitempty"`
+	AwardDate      *time.Time    `json:"fecha_buena_pro,omitempty"`
+	AwardDateRaw   string        `json:"fecha_buena_pro_original,omitempty"`
+	Participants   []Participant `json:"participantes"`
+}
+
+// Estados normalizados de un item.
+const (
+	StateAwarded    = "adjudicado"
+	StateConsented  = "consentido"
+	StateContracted = "contratado"
+	StateDeserted   = "desierto"
+	StateCancelled  = "cancelado"
+	StateNull       = "nulo"
+	StateSuspended  = "suspendido"
+	StateInProgress = "en_proceso"
+	StateUnknown    = "desconocido"
+)
+
+var stateKeywords = []struct {
+	keyword string
+	state   string
+}{
+	{"desiert", StateDeserted},
+	{"cancelad", StateCancelled},
+	{"nulo", StateNull},
+	{"nulidad", StateNull},
+	{"suspendid", StateSuspended},
+	{"contratad", StateContracted},
+	{"consentid", StateConsented},
+	{"adjudicad", StateAwarded},
+	{"buena pro", StateAwarded},
+	{"convocad", StateInProgress},
+	{"evaluacion", StateInProgress},
+	{"en proceso", StateInProgress},
+}
+
+// Participant es una fila de la tabla de postores de la ficha.
+type Participant struct {
+	Name          string   `json:"nombre"`
+	RUC           string   `json:"ruc,omitempty"`
+	MYPE          string   `json:"es_mype_original"`
+	Selva         string   `json:"es_selva_original"`
+	IsMYPE        Flag     `json:"es_mype"`
+	IsSelva       Flag     `json:"es_selva"`
+	Amount        string   `json:"monto,omitempty"`
+	AwardedAmount string   `json:"monto_adjudicado,omitempty"`
+	Score         string   `json:"puntaje,omitempty"`
+	Awarded       string   `json:"ganador_original,omitempty"`
+	IsAwarded     Flag     `json:"ganador"`
+	Columns       []Field  `json:"columnas"`
+	Consortium    bool     `json:"consorcio"`
+	Members       []Member `json:"integrantes,omitempty"`
+	FlagErrors    []string `json:"errores_si_no,omitempty"`
+}
+
+// Member es una de las empresas que forman un consorcio.
+type Member struct {
+	Name          string `json:"nombre"`
+	RUC           string `json:"ruc,omitempty"`
+	Participation string `json:"participacion,omitempty"`
+}
+
+// Field es una columna de la ficha tal como aparece en el portal.
+type Field struct {
+	Header string `json:"encabezado"`
+	Value  string `json:"valor"`
+}
+
+// Winner devuelve el postor con la buena pro. Si la tabla no indica quién
+// ganó se asume que es la primera fila, como lo muestra el portal, pero solo
+// cuando el item no tiene estado o su estado dice que se otorgó la buena pro.
+func (i Item) Winner() (Participant, bool) {
+	index := i.WinnerIndex()
+	if index < 0 {
+		return Participant{}, false
+	}
+	return i.Participants[index], true
+}
+
+// WinnerIndex devuelve la posición del ganador entre los postores, o -1 si el
+// item no tiene ganador.
+func (i Item) WinnerIndex() int {
+	if len(i.Participants) == 0 {
+		return -1
+	}
+
+	for index, participant := range i.Participants {
+		if participant.IsWinner() {
+			return index
+		}
+	}
+
+	for _, participant := range i.Participants {
+		if participant.IsAwarded != FlagUnknown {
+			return -1
+		}
+	}
+
+	if strings.TrimSpace(i.Status) == "" {
+		return 0
+	}
+	switch NormalizeState(i.Status, false) {
+	case StateAwarded, StateConsented, StateContracted:
+		return 0
+	}
+	return -1
+}
+
+// NormalizeState lleva el estado que muestra el portal a uno de los estados
+// normalizados. Sin estado, un item con ganador se considera adjudicado y uno
+// sin ganador todavía en proceso.
+func NormalizeState(status string, hasWinner bool) string {
+	normalized := Normalize(status)
+	if normalized == "" {
+		if hasWinner {
+			return StateAwarded
+		}
+		return StateInProgress
+	}
+
+	for _, candidate := range stateKeywords {
+		if strings.Contains(normalized, candidate.keyword) {
+			return candidate.state
+		}
+	}
+
+	return StateUnknown
+}
+
+// Resolved indica si todos los items del proceso tienen ganador o llegaron a
+// un estado final, es decir, si ya no hace falta volver a revisar la ficha.
+func (r Record) Resolved() bool {
+	if len(r.Items) == 0 {
+		return false
+	}
+
+	for _, item := range r.Items {
+		if _, hasWinner := item.Winner(); hasWinner {
+			continue
+		}
+		switch item.State {
+		case StateAwarded, StateConsented, StateContracted, StateDeserted, StateCancelled, StateNull:
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// NormalizeValue interpreta el valor y la moneda del proceso. Si alguno no se
+// puede interpretar se deja el error en el registro en lugar de un monto
+// incorrecto; los textos originales se conservan siempre.
+func (r *Record) NormalizeValue() {
+	var problems []string
+
+	amount, err := money.ParseDecimal(r.Value)
+	if err != nil {
+		problems = append(problems, err.Error())
+	} else {
+		r.Amount = amount
+	}
+
+	code, err := money.CurrencyCode(r.Currency)
+	if err != nil {
+		problems = append(problems, err.Error())
+	} else {
+		r.CurrencyCode = code
+	}
+
+	r.ValueError = strings.Join(problems, "; ")
+}
+
+// ProcessDate es la fecha de la primera etapa del cronograma que tiene fecha,
+// normalmente la convocatoria.
+func (r Record) ProcessDate() (time.Time, bool) {
+	for _, stage := range r.Schedule {
+		if stage.Start != nil {
+			return *stage.Start, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func IsConsortium(name string) bool {
+	return strings.HasPrefix(Normalize(name), "consorcio")
+}
+
+func (p Participant) IsWinner() bool {
+	return p.IsAwarded == FlagYes
+}
+
+// ParseAwarded interpreta la columna de buena pro, que además de un sí o un
+// no puede decir "ganador" o "adjudicado".
+func ParseAwarded(text string) (Flag, error) {
+	switch Normalize(text) {
+	case "ganador", "adjudicado":
+		return FlagYes, nil
+	}
+	return ParseFlag(text)
+}
+
+// NormalizeFlags interpreta las columnas MYPE, selva y buena pro del postor y
+// guarda en FlagErrors los valores que no se reconocieron. Los textos
+// originales se conservan.
+func (p *Participant) NormalizeFlags() {
+	p.FlagErrors = nil
+
+	var err error
+	if p.IsMYPE, err = ParseFlag(p.MYPE); err != nil {
+		p.FlagErrors = append(p.FlagErrors, "MYPE: "+err.Error())
+	}
+	if p.IsSelva, err = ParseFlag(p.Selva); err != nil {
+		p.FlagErrors = append(p.FlagErrors, "selva: "+err.Error())
+	}
+	if p.IsAwarded, err = ParseAwarded(p.Awarded); err != nil {
+		p.FlagErrors = append(p.FlagErrors, "buena pro: "+err.Error())
+	}
+}
+
+// Normalize quita tildes, espacios repetidos y mayúsculas para comparar
+// textos del portal.
+func Normalize(value string) string {
+	return text.Normalize(value)
+}
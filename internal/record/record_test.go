@@ -0,0 +1,99 @@
+package record
+
+import "testing"
+
+func TestNormalizeState(t *testing.T) {
+	tests := []struct {
+		status    string
+		hasWinner bool
+		want      string
+	}{
+		{status: "Adjudicado", want: StateAwarded},
+		{status: "Otorgamiento de la Buena Pro", want: StateAwarded},
+		{status: "CONSENTIDO", want: StateConsented},
+		{status: "Contratado", want: StateContracted},
+		{status: "Desierto", want: StateDeserted},
+		{status: "Cancelado", want: StateCancelled},
+		{status: "Nulo", want: StateNull},
+		{status: "Declarado nulidad", want: StateNull},
+		{status: "Suspendido", want: StateSuspended},
+		{status: "Convocado", want: StateInProgress},
+		{status: "En evaluación", want: StateInProgress},
+		{status: "  en   proceso ", want: StateInProgress},
+		{status: "Apelado", want: StateUnknown},
+		{status: "", hasWinner: true, want: StateAwarded},
+		{status: "", want: StateInProgress},
+		{status: "Desierto", hasWinner: true, want: StateDeserted},
+	}
+
+	for _, test := range tests {
+		if got := NormalizeState(test.status, test.hasWinner); got != test.want {
+			t.Errorf("NormalizeState(%q, %t) = %s, se esperaba %s", test.status, test.hasWinner, got, test.want)
+		}
+	}
+}
+
+func TestWinnerIndex(t *testing.T) {
+	unmarked := []Participant{{Name: "PRIMERO SAC"}, {Name: "SEGUNDO SAC"}}
+
+	tests := []struct {
+		name         string
+		status       string
+		participants []Participant
+		want         int
+	}{
+		{name: "sin postores", status: "Adjudicado", want: -1},
+		{name: "marcado como ganador", status: "Convocado", participants: []Participant{{Name: "PRIMERO SAC", Awarded: "No"}, {Name: "SEGUNDO SAC", Awarded: "Ganador"}}, want: 1},
+		{name: "marcado con sí", participants: []Participant{{Name: "PRIMERO SAC"}, {Name: "SEGUNDO SAC", Awarded: "SI"}}, want: 1},
+		{name: "marcado como adjudicado", participants: []Participant{{Name: "PRIMERO SAC", Awarded: "-"}, {Name: "SEGUNDO SAC", Awarded: "Adjudicado"}}, want: 1},
+		{name: "la tabla dice que nadie ganó", status: "Adjudicado", participants: []Participant{{Name: "PRIMERO SAC", Awarded: "No"}}, want: -1},
+		{name: "marca no reconocida", status: "Adjudicado", participants: []Participant{{Name: "PRIMERO SAC", Awarded: "Pendiente"}}, want: 0},
+		{name: "sin estado", participants: unmarked, want: 0},
+		{name: "adjudicado", status: "Adjudicado", participants: unmarked, want: 0},
+		{name: "consentido", status: "Consentido", participants: unmarked, want: 0},
+		{name: "contratado", status: "Contratado", participants: unmarked, want: 0},
+		{name: "convocado", status: "Convocado", participants: unmarked, want: -1},
+		{name: "en evaluación", status: "En evaluación", participants: unmarked, want: -1},
+		{name: "estado desconocido", status: "Apelado", participants: unmarked, want: -1},
+		{name: "desierto", status: "Desierto", participants: unmarked, want: -1},
+		{name: "cancelado", status: "Cancelado", participants: unmarked, want: -1},
+		{name: "nulo", status: "Nulo", participants: unmarked, want: -1},
+		{name: "suspendido", status: "Suspendido", participants: unmarked, want: -1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for i := range test.participants {
+				test.participants[i].NormalizeFlags()
+			}
+			item := Item{Status: test.status, Participants: test.participants}
+			if got := item.WinnerIndex(); got != test.want {
+				t.Errorf("WinnerIndex() = %d, se esperaba %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestResolved(t *testing.T) {
+	unmarked := []Participant{{Name: "PRIMERO SAC"}}
+
+	tests := []struct {
+		name  string
+		items []Item
+		want  bool
+	}{
+		{name: "sin items", want: false},
+		{name: "en evaluación con postores", items: []Item{{Status: "En evaluación", State: StateInProgress, Participants: unmarked}}, want: false},
+		{name: "adjudicado", items: []Item{{Status: "Adjudicado", State: StateAwarded, Participants: unmarked}}, want: true},
+		{name: "desierto", items: []Item{{Status: "Desierto", State: StateDeserted}}, want: true},
+		{name: "un item pendiente", items: []Item{{Status: "Desierto", State: StateDeserted}, {Status: "Convocado", State: StateInProgress, Participants: unmarked}}, want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := (Record{Items: test.items}).Resolved(); got != test.want {
+				t.Errorf("Resolved() = %t, se esperaba %t", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package record
+
+import (
+	"dieg0407/seace/internal/table"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// baseColumns es la cantidad de columnas que CSVWriter escribe por defecto,
+// las mismas de las primeras versiones del reporte. El resto se escriben solo
+// con extended.
+const baseColumns = 10
+
+var csvHeader = []string{
+	"Identificador",
+	"Entidad",
+	"Nomenclarura",
+	"Objecto",
+	"Descripción",
+	"Valor",
+	"Moneda",
+	"Ganador",
+	"Es MYPE",
+	"Es Selva",
+	"Item",
+	"Cantidad",
+	"Unidad",
+	"Valor Item",
+	"Estado Item",
+	"Estado",
+	"RUC Ganador",
+	"Monto Adjudicado",
+	"Fecha Buena Pro",
+	"RUC Entidad",
+	"Departamento",
+	"Provincia",
+	"Distrito",
+	"Ubigeo",
+	"Valor Decimal",
+	"Moneda ISO",
+	"Error Valor",
+	"Valor PEN",
+	"Tipo de Cambio",
+	"Fecha Tipo de Cambio",
+	"Error Conversión",
+	"Clave",
+	"Hash",
+	"Enlace",
+	"Etiquetas",
+}
+
+var participantsHeader = []string{"Identificador", "Nomenclatura", "Item", "Postor", "RUC", "Es MYPE", "Es Selva", "Monto", "Monto Adjudicado", "Puntaje", "Buena Pro", "Columnas"}
+var scheduleHeader = []string{"Identificador", "Nomenclatura", "Etapa", "Inicio", "Fin"}
+var membersHeader = []string{"Identificador", "Nomenclatura", "Item", "Consorcio", "Integrante", "RUC", "Participación"}
+
+// Writer recibe los registros extraídos y los envía a una salida.
+type Writer interface {
+	Write(Record) error
+	Close() error
+}
+
+// CSVWriter escribe una fila por cada item con ganador separando las columnas
+// por `;`, siendo la primera fila siempre la cabecera. Con includeAll también
+// se escriben los items sin ganador, con el ganador vacío. Por defecto solo se
+// escriben las primeras columnas del reporte; con extended se escriben todas.
+type CSVWriter struct {
+	table      *table.Writer
+	includeAll bool
+	extended   bool
+}
+
+func NewCSVWriter(out io.Writer, includeAll bool, extended bool) *CSVWriter {
+	columns := csvHeader
+	if !extended {
+		columns = csvHeader[:baseColumns]
+	}
+	return &CSVWriter{table: table.NewWriter(out, columns), includeAll: includeAll, extended: extended}
+}
+
+func (w *CSVWriter) Write(r Record) error {
+	rows := [][]string{}
+	for _, item := range r.Items {
+		winner, ok := item.Winner()
+		if !ok && !w.includeAll {
+			continue
+		}
+
+		row := []string{
+			fmt.Sprintf("%d", r.ID),
+			r.Entity,
+			r.Nomenclature,
+			r.ObjectType,
+			item.Description,
+			r.Value,
+			r.Currency,
+			winner.Name,
+			winner.IsMYPE.String(),
+			winner.IsSelva.String(),
+		}
+		if w.extended {
+			row = append(row,
+				fmt.Sprintf("%d", item.Number),
+				item.Quantity,
+				item.Unit,
+				item.ReferenceValue,
+				item.Status,
+				item.State,
+				winner.RUC,
+				item.AwardedAmount,
+				formatTime(item.AwardDate),
+				r.EntityRUC,
+				r.Department,
+				r.Province,
+				r.District,
+				r.Ubigeo,
+				r.Amount.String(),
+				r.CurrencyCode,
+				r.ValueError,
+				r.AmountPEN.String(),
+				r.ExchangeRate.String(),
+				r.ExchangeRateDate,
+				r.ConversionError,
+				r.Key,
+				r.Hash,
+				r.Permalink,
+				strings.Join(r.Tags, ", "),
+			)
+		}
+		rows = append(rows, row)
+	}
+
+	return w.table.Write(rows...)
+}
+
+func (w *CSVWriter) Close() error {
+	return w.table.Close()
+}
+
+// JSONWriter escribe un registro por línea con sus listas anidadas.
+type JSONWriter struct {
+	encoder *json.Encoder
+}
+
+func NewJSONWriter(out io.Writer) *JSONWriter {
+	return &JSONWriter{encoder: json.NewEncoder(out)}
+}
+
+func (w *JSONWriter) Write(r Record) error {
+	return w.encoder.Encode(r)
+}
+
+func (w *JSONWriter) Close() error {
+	return nil
+}
+
+// ParticipantsWriter escribe una fila por cada postor de cada proceso.
+type ParticipantsWriter struct {
+	table *table.Writer
+}
+
+func NewParticipantsWriter(out io.Writer) *ParticipantsWriter {
+	return &ParticipantsWriter{table: table.NewWriter(out, participantsHeader)}
+}
+
+func (w *ParticipantsWriter) Write(r Record) error {
+	rows := [][]string{}
+	for _, item := range r.Items {
+		for _, participant := range item.Participants {
+			rows = append(rows, []string{
+				fmt.Sprintf("%d", r.ID),
+				r.Nomenclature,
+				fmt.Sprintf("%d", item.Number),
+				participant.Name,
+				participant.RUC,
+				participant.IsMYPE.String(),
+				participant.IsSelva.String(),
+				participant.Amount,
+				participant.AwardedAmount,
+				participant.Score,
+				participant.IsAwarded.String(),
+				formatColumns(participant.Columns),
+			})
+		}
+	}
+
+	return w.table.Write(rows...)
+}
+
+func (w *ParticipantsWriter) Close() error {
+	return w.table.Close()
+}
+
+func formatColumns(columns []Field) string {
+	parts := make([]string, 0, len(columns))
+	for _, column := range columns {
+		parts = append(parts, fmt.Sprintf("%s: %s", column.Header, column.Value))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// ScheduleWriter escribe una fila por cada etapa del cronograma de cada
+// proceso, con las fechas en formato RFC 3339 en la hora de Lima.
+type ScheduleWriter struct {
+	table *table.Writer
+}
+
+func NewScheduleWriter(out io.Writer) *ScheduleWriter {
+	return &ScheduleWriter{table: table.NewWriter(out, scheduleHeader)}
+}
+
+func (w *ScheduleWriter) Write(r Record) error {
+	rows := [][]string{}
+	for _, stage := range r.Schedule {
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", r.ID),
+			r.Nomenclature,
+			stage.Name,
+			formatTime(stage.Start),
+			formatTime(stage.End),
+		})
+	}
+
+	return w.table.Write(rows...)
+}
+
+func (w *ScheduleWriter) Close() error {
+	return w.table.Close()
+}
+
+func formatTime(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return value.Format(time.RFC3339)
+}
+
+// MembersWriter escribe una fila por cada integrante de los consorcios que se
+// presentaron, enlazada al proceso, al item y al consorcio.
+type MembersWriter struct {
+	table *table.Writer
+}
+
+func NewMembersWriter(out io.Writer) *MembersWriter {
+	return &MembersWriter{table: table.NewWriter(out, membersHeader)}
+}
+
+func (w *MembersWriter) Write(r Record) error {
+	rows := [][]string{}
+	for _, item := range r.Items {
+		for _, participant := range item.Participants {
+			for _, member := range participant.Members {
+				rows = append(rows, []string{
+					fmt.Sprintf("%d", r.ID),
+					r.Nomenclature,
+					fmt.Sprintf("%d", item.Number),
+					participant.Name,
+					member.Name,
+					member.RUC,
+					member.Participation,
+				})
+			}
+		}
+	}
+
+	return w.table.Write(rows...)
+}
+
+func (w *MembersWriter) Close() error {
+	return w.table.Close()
+}
@@ -0,0 +1,80 @@
+package record
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCSVWriter(t *testing.T) {
+	data := Record{
+		ID:           7,
+		Entity:       `MUNICIPALIDAD "LIMA"; NORTE`,
+		Nomenclature: "AS-SM-12-2024-MPL-1",
+		ObjectType:   "Bien",
+		Value:        "1,000.00",
+		Currency:     "Soles",
+		Items: []Item{
+			{Number: 1, Description: "Compra de \"cemento\"\nportland", Participants: []Participant{{Name: "EMPRESA; SAC", IsAwarded: FlagYes}}},
+			{Number: 2, Description: "Sin ganador"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		extended bool
+		columns  int
+	}{
+		{name: "columnas base", columns: baseColumns},
+		{name: "columnas extendidas", extended: true, columns: len(csvHeader)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			writer := NewCSVWriter(out, false, test.extended)
+			if err := writer.Write(data); err != nil {
+				t.Fatalf("Write() = %v", err)
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("Close() = %v", err)
+			}
+
+			header, _, _ := strings.Cut(out.String(), "\n")
+			if got := len(strings.Split(header, ";")); got != test.columns {
+				t.Errorf("la cabecera tiene %d columnas, se esperaba %d", got, test.columns)
+			}
+
+			records, err := ReadCSV(out)
+			if err != nil {
+				t.Fatalf("ReadCSV() = %v", err)
+			}
+			if len(records) != 1 || len(records[0].Items) != 1 {
+				t.Fatalf("ReadCSV() = %+v, se esperaba un registro con un item", records)
+			}
+			got := records[0]
+			if got.Entity != data.Entity {
+				t.Errorf("Entity = %q, se esperaba %q", got.Entity, data.Entity)
+			}
+			if got.Items[0].Description != data.Items[0].Description {
+				t.Errorf("Description = %q, se esperaba %q", got.Items[0].Description, data.Items[0].Description)
+			}
+			if winner, _ := got.Items[0].Winner(); winner.Name != "EMPRESA; SAC" {
+				t.Errorf("Winner() = %q, se esperaba %q", winner.Name, "EMPRESA; SAC")
+			}
+		})
+	}
+}
+
+func TestCSVWriterHeaderWithoutRecords(t *testing.T) {
+	out := &bytes.Buffer{}
+	writer := NewCSVWriter(out, false, false)
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	want := strings.Join(csvHeader[:baseColumns], ";") + "\n"
+	if out.String() != want {
+		t.Errorf("Close() escribió %q, se esperaba %q", out.String(), want)
+	}
+}
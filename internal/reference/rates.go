@@ -0,0 +1,119 @@
+package reference
+
+import (
+	"dieg0407/seace/internal/money"
+	"dieg0407/seace/internal/record"
+	"dieg0407/seace/internal/table"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"time"
+)
+
+const rateDateFormat = "2006-01-02"
+
+// Rate es el tipo de cambio de una moneda a soles en una fecha.
+type Rate struct {
+	Date     time.Time
+	Currency string
+	Value    money.Decimal
+}
+
+// Rates guarda los tipos de cambio de cada moneda ordenados por fecha.
+type Rates struct {
+	byCurrency map[string][]Rate
+}
+
+// LoadRates lee la tabla de tipos de cambio con las columnas
+// fecha;moneda;tipo_cambio. La fecha va como AAAA-MM-DD y la moneda como
+// código ISO o como la escribe el portal.
+func LoadRates(path string) (*Rates, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo abrir la tabla de tipos de cambio:\n%w", err)
+	}
+	defer file.Close()
+
+	return parseRates(file)
+}
+
+func parseRates(input io.Reader) (*Rates, error) {
+	rates := &Rates{byCurrency: map[string][]Rate{}}
+
+	err := table.Read(input, "la tabla de tipos de cambio", 3, func(line int, columns []string) error {
+		if columns[0] == "" || columns[1] == "" || columns[2] == "" {
+			return fmt.Errorf("la línea %d de la tabla de tipos de cambio no tiene fecha, moneda y tipo de cambio", line)
+		}
+
+		date, err := time.ParseInLocation(rateDateFormat, columns[0], time.UTC)
+		if err != nil {
+			return fmt.Errorf("la fecha de la línea %d de la tabla de tipos de cambio es inválida:\n%w", line, err)
+		}
+		currency, err := money.CurrencyCode(columns[1])
+		if err != nil {
+			return fmt.Errorf("la moneda de la línea %d de la tabla de tipos de cambio es inválida:\n%w", line, err)
+		}
+		value, err := money.ParseDecimal(columns[2])
+		if err != nil {
+			return fmt.Errorf("el tipo de cambio de la línea %d es inválido:\n%w", line, err)
+		}
+
+		rates.byCurrency[currency] = append(rates.byCurrency[currency], Rate{Date: date, Currency: currency, Value: value})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, list := range rates.byCurrency {
+		sort.Slice(list, func(i, j int) bool { return list[i].Date.Before(list[j].Date) })
+	}
+
+	return rates, nil
+}
+
+// Find devuelve el tipo de cambio de la fecha o, si ese día no hay, el de la
+// fecha anterior más cercana.
+func (r *Rates) Find(currency string, date time.Time) (Rate, bool) {
+	list := r.byCurrency[currency]
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	index := sort.Search(len(list), func(i int) bool { return list[i].Date.After(day) })
+	if index == 0 {
+		return Rate{}, false
+	}
+	return list[index-1], true
+}
+
+// Convert llena el valor en soles del registro y el tipo de cambio usado,
+// tomando como fecha la del inicio del cronograma. Si no se puede convertir
+// se deja el motivo en el registro.
+func (r *Rates) Convert(data *record.Record) {
+	if r == nil || data.Amount.IsZero() {
+		return
+	}
+
+	if data.CurrencyCode == "PEN" {
+		data.AmountPEN = data.Amount
+		data.ExchangeRate = money.NewDecimal(big.NewRat(1, 1), 2)
+		return
+	}
+
+	date, ok := data.ProcessDate()
+	if !ok {
+		data.ConversionError = "el proceso no tiene fecha en el cronograma"
+		return
+	}
+
+	rate, ok := r.Find(data.CurrencyCode, date)
+	if !ok {
+		data.ConversionError = fmt.Sprintf("no hay tipo de cambio de %s al %s o antes", data.CurrencyCode, date.Format(rateDateFormat))
+		return
+	}
+
+	data.AmountPEN = data.Amount.Mul(rate.Value)
+	data.ExchangeRate = rate.Value
+	data.ExchangeRateDate = rate.Date.Format(rateDateFormat)
+}
@@ -0,0 +1,129 @@
+package reference
+
+import (
+	"dieg0407/seace/internal/money"
+	"dieg0407/seace/internal/record"
+	"strings"
+	"testing"
+	"time"
+)
+
+const ratesTable = `fecha;moneda;tipo_cambio
+2024-03-04;USD;3.752
+2024-03-01;Dólares Americanos;3.745
+2024-03-01;EUR;4.051
+`
+
+func mustParseRates(t *testing.T) *Rates {
+	t.Helper()
+	rates, err := parseRates(strings.NewReader(ratesTable))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rates
+}
+
+func TestRatesFind(t *testing.T) {
+	rates := mustParseRates(t)
+	lima := time.FixedZone("Lima", -5*60*60)
+
+	tests := []struct {
+		name     string
+		currency string
+		date     time.Time
+		want     string
+		wantDate string
+	}{
+		{name: "mismo día", currency: "USD", date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), want: "3.745", wantDate: "2024-03-01"},
+		{name: "fin de semana usa el anterior", currency: "USD", date: time.Date(2024, 3, 3, 12, 0, 0, 0, time.UTC), want: "3.745", wantDate: "2024-03-01"},
+		{name: "hora de Lima al final del día", currency: "USD", date: time.Date(2024, 3, 4, 23, 30, 0, 0, lima), want: "3.752", wantDate: "2024-03-04"},
+		{name: "posterior al último", currency: "USD", date: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), want: "3.752", wantDate: "2024-03-04"},
+		{name: "anterior al primero", currency: "USD", date: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{name: "moneda sin tabla", currency: "GBP", date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "otra moneda", currency: "EUR", date: time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), want: "4.051", wantDate: "2024-03-01"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rate, ok := rates.Find(test.currency, test.date)
+			if ok != (test.want != "") {
+				t.Fatalf("Find() ok = %t, se esperaba %t", ok, test.want != "")
+			}
+			if !ok {
+				return
+			}
+			if rate.Value.String() != test.want || rate.Date.Format(rateDateFormat) != test.wantDate {
+				t.Errorf("Find() = %s del %s, se esperaba %s del %s", rate.Value, rate.Date.Format(rateDateFormat), test.want, test.wantDate)
+			}
+		})
+	}
+}
+
+func TestRatesConvert(t *testing.T) {
+	rates := mustParseRates(t)
+	march := time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+	amount, _ := money.ParseDecimal("1,000.00")
+
+	tests := []struct {
+		name      string
+		data      record.Record
+		wantPEN   string
+		wantRate  string
+		wantError bool
+	}{
+		{
+			name:     "soles",
+			data:     record.Record{Amount: amount, CurrencyCode: "PEN"},
+			wantPEN:  "1000.00",
+			wantRate: "1.00",
+		},
+		{
+			name:     "dólares",
+			data:     record.Record{Amount: amount, CurrencyCode: "USD", Schedule: []record.Stage{{Start: &march}}},
+			wantPEN:  "3745.00",
+			wantRate: "3.745",
+		},
+		{
+			name:      "sin cronograma",
+			data:      record.Record{Amount: amount, CurrencyCode: "USD"},
+			wantError: true,
+		},
+		{
+			name:      "sin tipo de cambio",
+			data:      record.Record{Amount: amount, CurrencyCode: "GBP", Schedule: []record.Stage{{Start: &march}}},
+			wantError: true,
+		},
+		{
+			name: "sin monto",
+			data: record.Record{CurrencyCode: "USD", Schedule: []record.Stage{{Start: &march}}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := test.data
+			rates.Convert(&data)
+			if (data.ConversionError != "") != test.wantError {
+				t.Fatalf("Convert() error = %q, se esperaba error: %t", data.ConversionError, test.wantError)
+			}
+			if data.AmountPEN.String() != test.wantPEN || data.ExchangeRate.String() != test.wantRate {
+				t.Errorf("Convert() = %s con %s, se esperaba %s con %s", data.AmountPEN, data.ExchangeRate, test.wantPEN, test.wantRate)
+			}
+		})
+	}
+}
+
+func TestParseRatesErrors(t *testing.T) {
+	tests := []string{
+		"fecha;moneda;tipo_cambio\n01/03/2024;USD;3.745\n",
+		"fecha;moneda;tipo_cambio\n2024-03-01;Bitcoin;3.745\n",
+		"fecha;moneda;tipo_cambio\n2024-03-01;USD;tres\n",
+		"fecha;moneda;tipo_cambio\n2024-03-01;USD\n",
+	}
+
+	for _, table := range tests {
+		if _, err := parseRates(strings.NewReader(table)); err == nil {
+			t.Errorf("parseRates(%q) no devolvió un error", table)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package reference
+
+import (
+	"dieg0407/seace/internal/record"
+	"dieg0407/seace/internal/table"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+//go:embed departamentos.csv
+var departmentsCSV string
+
+var departments = mustParseDepartments(departmentsCSV)
+
+// Entity es una fila de la tabla de referencia de entidades contratantes.
+type Entity struct {
+	Name     string
+	RUC      string
+	Ubigeo   string
+	Province string
+	District string
+}
+
+// Entities permite buscar los datos de una entidad por su nombre o su RUC.
+type Entities struct {
+	byName map[string]Entity
+	byRUC  map[string]Entity
+}
+
+// LoadEntities lee la tabla de entidades con las columnas
+// nombre;ruc;ubigeo;provincia;distrito.
+func LoadEntities(path string) (*Entities, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo abrir la tabla de entidades:\n%w", err)
+	}
+	defer file.Close()
+
+	return parseEntities(file)
+}
+
+func parseEntities(input io.Reader) (*Entities, error) {
+	entities := &Entities{byName: map[string]Entity{}, byRUC: map[string]Entity{}}
+
+	err := table.Read(input, "la tabla de entidades", 5, func(line int, columns []string) error {
+		entity := Entity{
+			Name:     columns[0],
+			RUC:      columns[1],
+			Ubigeo:   columns[2],
+			Province: columns[3],
+			District: columns[4],
+		}
+		if entity.Name == "" && entity.RUC == "" {
+			return fmt.Errorf("la línea %d de la tabla de entidades no tiene nombre ni ruc", line)
+		}
+
+		if entity.Name != "" {
+			entities.byName[record.Normalize(entity.Name)] = entity
+		}
+		if entity.RUC != "" {
+			entities.byRUC[entity.RUC] = entity
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entities, nil
+}
+
+// Enrich completa el RUC y la ubicación de la entidad del registro. Los datos
+// que ya vienen de la ficha tienen prioridad sobre los de la tabla.
+func (e *Entities) Enrich(r *record.Record) {
+	if e != nil {
+		entity, ok := e.byRUC[r.EntityRUC]
+		if !ok {
+			entity, ok = e.byName[record.Normalize(r.Entity)]
+		}
+		if ok {
+			r.EntityRUC = firstNonEmpty(r.EntityRUC, entity.RUC)
+			r.Ubigeo = firstNonEmpty(r.Ubigeo, entity.Ubigeo)
+			r.Province = firstNonEmpty(r.Province, entity.Province)
+			r.District = firstNonEmpty(r.District, entity.District)
+		}
+	}
+
+	if r.Department == "" && len(r.Ubigeo) >= 2 {
+		r.Department = departments[r.Ubigeo[:2]]
+	}
+	r.Department = Department(r.Department)
+}
+
+// Department devuelve el nombre oficial del departamento, sin importar las
+// tildes o mayúsculas con las que se escribió.
+func Department(name string) string {
+	normalized := record.Normalize(name)
+	for _, department := range departments {
+		if record.Normalize(department) == normalized {
+			return department
+		}
+	}
+	return name
+}
+
+func mustParseDepartments(content string) map[string]string {
+	result := map[string]string{}
+	err := table.Read(strings.NewReader(content), "la tabla de departamentos", 2, func(line int, columns []string) error {
+		if columns[0] == "" || columns[1] == "" {
+			return fmt.Errorf("la línea %d de la tabla de departamentos no tiene código y nombre", line)
+		}
+		result[columns[0]] = columns[1]
+		return nil
+	})
+	if err != nil {
+		panic(err.Error())
+	}
+	return result
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,90 @@
+package reference
+
+import (
+	"dieg0407/seace/internal/record"
+	"strings"
+	"testing"
+)
+
+const entitiesTable = `nombre;ruc;ubigeo;provincia;distrito
+Municipalidad Distrital de Miraflores;20131377224;150122;Lima;Miraflores
+GOBIERNO REGIONAL DE ÁNCASH;20530689019;020101;Huaraz;Huaraz
+`
+
+func TestEntitiesEnrich(t *testing.T) {
+	entities, err := parseEntities(strings.NewReader(entitiesTable))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data record.Record
+		want record.Record
+	}{
+		{
+			name: "por ruc",
+			data: record.Record{Entity: "MUNICIPALIDAD DE MIRAFLORES", EntityRUC: "20131377224"},
+			want: record.Record{EntityRUC: "20131377224", Ubigeo: "150122", Department: "Lima", Province: "Lima", District: "Miraflores"},
+		},
+		{
+			name: "por nombre sin tildes ni mayúsculas",
+			data: record.Record{Entity: "gobierno regional de ancash"},
+			want: record.Record{EntityRUC: "20530689019", Ubigeo: "020101", Department: "Áncash", Province: "Huaraz", District: "Huaraz"},
+		},
+		{
+			name: "la ficha tiene prioridad",
+			data: record.Record{Entity: "Gobierno Regional de Áncash", Province: "Santa", District: "Chimbote", Department: "ANCASH"},
+			want: record.Record{EntityRUC: "20530689019", Ubigeo: "020101", Department: "Áncash", Province: "Santa", District: "Chimbote"},
+		},
+		{
+			name: "entidad desconocida",
+			data: record.Record{Entity: "Entidad que no está en la tabla", Department: "apurimac"},
+			want: record.Record{Department: "Apurímac"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := test.data
+			entities.Enrich(&data)
+			got := [5]string{data.EntityRUC, data.Ubigeo, data.Department, data.Province, data.District}
+			want := [5]string{test.want.EntityRUC, test.want.Ubigeo, test.want.Department, test.want.Province, test.want.District}
+			if got != want {
+				t.Errorf("Enrich() = %q, se esperaba %q", got, want)
+			}
+		})
+	}
+}
+
+func TestEnrichWithoutTable(t *testing.T) {
+	var entities *Entities
+	data := record.Record{Entity: "Municipalidad Distrital de Miraflores", Ubigeo: "150122"}
+
+	entities.Enrich(&data)
+	if data.Department != "Lima" {
+		t.Errorf("Enrich() sin tabla = %q, se esperaba el departamento del ubigeo", data.Department)
+	}
+}
+
+func TestDepartment(t *testing.T) {
+	tests := map[string]string{
+		"ANCASH":        "Áncash",
+		"  san  martin": "San Martín",
+		"Lima":          "Lima",
+		"Desconocido":   "Desconocido",
+		"":              "",
+	}
+
+	for name, want := range tests {
+		if got := Department(name); got != want {
+			t.Errorf("Department(%q) = %q, se esperaba %q", name, got, want)
+		}
+	}
+}
+
+func TestParseEntitiesErrors(t *testing.T) {
+	if _, err := parseEntities(strings.NewReader("nombre;ruc;ubigeo;provincia;distrito\n;;150122;Lima;Miraflores\n")); err == nil {
+		t.Error("parseEntities() no devolvió un error para una fila sin nombre ni ruc")
+	}
+}
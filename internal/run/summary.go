@@ -0,0 +1,77 @@
+package run
+
+import (
+	"dieg0407/seace/internal/record"
+	"time"
+)
+
+// Award es un item con ganador de un registro escrito en la ejecución.
+type Award struct {
+	Key          string `json:"clave"`
+	Nomenclature string `json:"nomenclatura"`
+	Entity       string `json:"entidad"`
+	Item         int    `json:"item"`
+	Description  string `json:"descripcion"`
+	Winner       string `json:"ganador"`
+	WinnerRUC    string `json:"ruc_ganador,omitempty"`
+	Amount       string `json:"monto_adjudicado,omitempty"`
+	State        string `json:"estado"`
+	Permalink    string `json:"enlace,omitempty"`
+}
+
+// Summary resume lo que hizo una ejecución. Se entrega a los Reporter al
+// terminar, haya fallado o no.
+type Summary struct {
+	Run        string    `json:"ejecucion"`
+	Started    time.Time `json:"inicio"`
+	Finished   time.Time `json:"fin"`
+	Processed  int       `json:"procesados"`
+	Written    int       `json:"escritos"`
+	Duplicated int       `json:"duplicados"`
+	Unchanged  int       `json:"sin_cambios"`
+	Pending    int       `json:"en_seguimiento"`
+	Tagged     int       `json:"etiquetados"`
+	Alerts     int       `json:"alertas"`
+	Awards     []Award   `json:"adjudicaciones"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Reporter recibe el resumen al final de cada ejecución.
+type Reporter interface {
+	RunFinished(Summary) error
+}
+
+func NewSummary(run string) *Summary {
+	return &Summary{Run: run, Started: time.Now(), Awards: []Award{}}
+}
+
+// Record cuenta el registro escrito y guarda sus items con ganador.
+func (s *Summary) Record(data record.Record) {
+	s.Written++
+	for _, item := range data.Items {
+		winner, ok := item.Winner()
+		if !ok {
+			continue
+		}
+		s.Awards = append(s.Awards, Award{
+			Key:          data.Key,
+			Nomenclature: data.Nomenclature,
+			Entity:       data.Entity,
+			Item:         item.Number,
+			Description:  item.Description,
+			Winner:       winner.Name,
+			WinnerRUC:    winner.RUC,
+			Amount:       item.AwardedAmount,
+			State:        item.State,
+			Permalink:    data.Permalink,
+		})
+	}
+}
+
+// Finish cierra el resumen con el resultado de la ejecución.
+func (s *Summary) Finish(err error) {
+	s.Finished = time.Now()
+	if err != nil {
+		s.Error = err.Error()
+	}
+}
@@ -0,0 +1,192 @@
+package scrapper
+
+import (
+	"dieg0407/seace/internal/record"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tebeka/selenium"
+)
+
+const (
+	consortiumDialogSelector = ".ui-dialog[aria-hidden='false']"
+	consortiumCloseSelector  = ".ui-dialog-titlebar-close"
+	consortiumWaitTimeout    = 10 * time.Second
+)
+
+var consortiumLinkKeywords = []string{"consorci", "integrante", "miembro"}
+
+var participationPattern = regexp.MustCompile(`\d{1,3}(?:[.,]\d+)?\s*%`)
+var rucLabelPattern = regexp.MustCompile(`(?i)\bruc\b\s*:?`)
+
+// extractConsortiumMembers abre el detalle del consorcio desde la fila del
+// postor y lee a sus integrantes. Si el portal no ofrece el detalle, los
+// integrantes se leen del texto del postor cuando los muestra con su RUC; si
+// tampoco, se devuelve una lista vacía.
+func extractConsortiumMembers(driver selenium.WebDriver, row selenium.WebElement, participant record.Participant) ([]record.Member, error) {
+	link, err := findConsortiumLink(row)
+	if err != nil {
+		return nil, err
+	}
+	if link == nil {
+		return parseMembersText(participant.Name), nil
+	}
+
+	if err := click(driver, link, pageAction, nil); err != nil {
+		return nil, fmt.Errorf("error al abrir el detalle del consorcio:\n%s", err)
+	}
+
+	var dialog selenium.WebElement
+	err = driver.WaitWithTimeout(func(wd selenium.WebDriver) (bool, error) {
+		dialog, err = wd.FindElement(selenium.ByCSSSelector, consortiumDialogSelector)
+		return err == nil, nil
+	}, consortiumWaitTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("error al esperar el detalle del consorcio:\n%s", err)
+	}
+
+	members, err := extractMembers(dialog)
+	if err != nil {
+		return nil, err
+	}
+
+	closeButton, err := dialog.FindElement(selenium.ByCSSSelector, consortiumCloseSelector)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener el botón para cerrar el detalle del consorcio:\n%s", err)
+	}
+	if err := click(driver, closeButton, pageAction, nil); err != nil {
+		return nil, fmt.Errorf("error al cerrar el detalle del consorcio:\n%s", err)
+	}
+
+	return members, nil
+}
+
+func findConsortiumLink(row selenium.WebElement) (selenium.WebElement, error) {
+	links, err := row.FindElements(selenium.ByCSSSelector, "a, button")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, link := range links {
+		text, err := link.Text()
+		if err != nil {
+			return nil, err
+		}
+		title, err := link.GetAttribute("title")
+		if err != nil {
+			title = ""
+		}
+
+		normalized := record.Normalize(text + " " + title)
+		for _, keyword := range consortiumLinkKeywords {
+			if strings.Contains(normalized, keyword) {
+				return link, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+func extractMembers(dialog selenium.WebElement) ([]record.Member, error) {
+	table, err := dialog.FindElement(selenium.ByTagName, "table")
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener la tabla de integrantes:\n%s", err)
+	}
+	headers, err := extractHeaders(table)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := table.FindElements(selenium.ByCSSSelector, "tbody tr")
+	if err != nil {
+		return nil, err
+	}
+
+	members := []record.Member{}
+	for _, row := range rows {
+		columns, err := row.FindElements(selenium.ByTagName, "td")
+		if err != nil {
+			return nil, err
+		}
+		if len(columns) <= 1 {
+			continue
+		}
+
+		values := make([]string, 0, len(columns))
+		for _, column := range columns {
+			text, err := column.Text()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, strings.TrimSpace(text))
+		}
+		members = append(members, parseMemberRow(headers, values))
+	}
+
+	return members, nil
+}
+
+// parseMemberRow arma un integrante con las columnas de una fila del detalle
+// del consorcio. Si no hay una columna de RUC se busca en el nombre.
+func parseMemberRow(headers []string, values []string) record.Member {
+	member := record.Member{}
+	for i, value := range values {
+		header := ""
+		if i < len(headers) {
+			header = headers[i]
+		}
+		assignMemberField(&member, header, i, value)
+	}
+	if member.RUC == "" {
+		member.RUC = rucPattern.FindString(member.Name)
+	}
+	return member
+}
+
+// parseMembersText lee los integrantes que el portal muestra como texto en la
+// celda del postor, debajo del nombre del consorcio: una línea por integrante
+// con su RUC y, si lo tiene, su porcentaje de participación. Las líneas sin
+// RUC se ignoran.
+func parseMembersText(text string) []record.Member {
+	members := []record.Member{}
+	lines := strings.Split(text, "\n")
+	for _, line := range lines[1:] {
+		ruc := rucPattern.FindString(line)
+		if ruc == "" {
+			continue
+		}
+
+		participation := participationPattern.FindString(line)
+		name := strings.Replace(line, ruc, "", 1)
+		if participation != "" {
+			name = strings.Replace(name, participation, "", 1)
+		}
+		name = rucLabelPattern.ReplaceAllString(name, "")
+		name = strings.Trim(strings.Join(strings.Fields(name), " "), " -–:;,()[]|")
+
+		members = append(members, record.Member{
+			Name:          name,
+			RUC:           ruc,
+			Participation: strings.ReplaceAll(participation, " ", ""),
+		})
+	}
+	return members
+}
+
+func assignMemberField(member *record.Member, header string, index int, value string) {
+	normalized := record.Normalize(header)
+
+	switch {
+	case strings.Contains(normalized, "ruc"):
+		member.RUC = value
+	case strings.Contains(normalized, "particip"), strings.Contains(normalized, "%"), strings.Contains(value, "%"):
+		member.Participation = value
+	case strings.Contains(normalized, "nombre"), strings.Contains(normalized, "razon social"), strings.Contains(normalized, "integrante"):
+		member.Name = value
+	case header == "" && index == 0:
+		member.Name = value
+	}
+}
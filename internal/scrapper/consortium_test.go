@@ -0,0 +1,88 @@
+package scrapper
+
+import (
+	"dieg0407/seace/internal/record"
+	"reflect"
+	"testing"
+)
+
+func TestParseMemberRow(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		values  []string
+		want    record.Member
+	}{
+		{
+			name:    "con cabeceras",
+			headers: []string{"Nombre o Razón Social", "RUC", "% Participación"},
+			values:  []string{"EMPRESA A SAC", "20123456789", "60%"},
+			want:    record.Member{Name: "EMPRESA A SAC", RUC: "20123456789", Participation: "60%"},
+		},
+		{
+			name:    "participación sin cabecera",
+			headers: []string{"Integrante", "RUC", ""},
+			values:  []string{"EMPRESA B EIRL", "20987654321", "40 %"},
+			want:    record.Member{Name: "EMPRESA B EIRL", RUC: "20987654321", Participation: "40 %"},
+		},
+		{
+			name:   "sin cabeceras con el ruc en el nombre",
+			values: []string{"20123456789 - EMPRESA A SAC", "50%"},
+			want:   record.Member{Name: "20123456789 - EMPRESA A SAC", RUC: "20123456789", Participation: "50%"},
+		},
+		{
+			name:    "sin ruc",
+			headers: []string{"Integrante", "Participación"},
+			values:  []string{"PERSONA NATURAL", "10"},
+			want:    record.Member{Name: "PERSONA NATURAL", Participation: "10"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := parseMemberRow(test.headers, test.values); got != test.want {
+				t.Errorf("parseMemberRow() = %+v, se esperaba %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseMembersText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []record.Member
+	}{
+		{
+			name: "integrantes con ruc y participación",
+			text: "CONSORCIO NORTE\nEMPRESA A SAC - RUC: 20123456789 - 60%\n20987654321 EMPRESA B EIRL (40.5 %)",
+			want: []record.Member{
+				{Name: "EMPRESA A SAC", RUC: "20123456789", Participation: "60%"},
+				{Name: "EMPRESA B EIRL", RUC: "20987654321", Participation: "40.5%"},
+			},
+		},
+		{
+			name: "sin participación",
+			text: "CONSORCIO SUR\nEMPRESA A SAC 20123456789",
+			want: []record.Member{{Name: "EMPRESA A SAC", RUC: "20123456789"}},
+		},
+		{
+			name: "el ruc del consorcio no es un integrante",
+			text: "CONSORCIO ESTE 20600000001\nEMPRESA A SAC 20123456789",
+			want: []record.Member{{Name: "EMPRESA A SAC", RUC: "20123456789"}},
+		},
+		{
+			name: "solo el nombre",
+			text: "CONSORCIO OESTE",
+			want: []record.Member{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := parseMembersText(test.text); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseMembersText() = %+v, se esperaba %+v", got, test.want)
+			}
+		})
+	}
+}
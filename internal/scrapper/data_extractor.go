@@ -1,93 +1,172 @@
 package scrapper
 
 import (
+	"dieg0407/seace/internal/record"
 	"fmt"
 	"log"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/tebeka/selenium"
 )
 
-const nomenclatureXPath = "/html/body/div[3]/div/div/div/div/form/table[2]/tbody/tr[1]/td[1]/table/tbody/tr/td/fieldset/div/table/tbody/tr[2]/td/table/tbody/tr[1]/td[2]"
-const entityXPath = "/html/body/div[3]/div/div/div/div/form/table[2]/tbody/tr[1]/td[1]/table/tbody/tr/td/fieldset/div/table/tbody/tr[6]/td/table/tbody/tr[1]/td[2]"
-const objectTypeXPath = "/html/body/div[3]/div/div/div/div/form/table[2]/tbody/tr[1]/td[1]/table/tbody/tr/td/fieldset/div/table/tbody/tr[9]/td/table/tbody/tr[1]/td[2]"
-const valueXPath = "/html/body/div[3]/div/div/div/div/form/table[2]/tbody/tr[1]/td[1]/table/tbody/tr/td/fieldset/div/table/tbody/tr[9]/td/table/tbody/tr[3]/td[2]/span[1]"
-const currencyXPath = "/html/body/div[3]/div/div/div/div/form/table[2]/tbody/tr[1]/td[1]/table/tbody/tr/td/fieldset/div/table/tbody/tr[9]/td/table/tbody/tr[3]/td[2]/span[2]"
-const winnerElement = "tbFicha:idGridLstItems:0:dtParticipantes_data"
-const printTemplate = "%s;\"%s\";\"%s\";%s;\"%s\";%s;%s;\"%s\";%s;%s\n"
-
-func printHeader() {
-	stdout := log.New(os.Stdout, "", 0)
-	stdout.Printf(printTemplate,
-		"Identificador",
-		"Entidad",
-		"Nomenclarura",
-		"Objecto",
-		"Descripción",
-		"Valor",
-		"Moneda",
-		"Ganador",
-		"Es MYPE",
-		"Es Selva",
-	)
-}
-
-func extractData(driver selenium.WebDriver, id int) error {
-	stdout := log.New(os.Stdout, "", 0)
+const fichaFieldsetXPath = "/html/body/div[3]/div/div/div/div/form/table[2]/tbody/tr[1]/td[1]/table/tbody/tr/td/fieldset"
+const nomenclatureXPath = fichaFieldsetXPath + "/div/table/tbody/tr[2]/td/table/tbody/tr[1]/td[2]"
+const entityXPath = fichaFieldsetXPath + "/div/table/tbody/tr[6]/td/table/tbody/tr[1]/td[2]"
+const objectTypeXPath = fichaFieldsetXPath + "/div/table/tbody/tr[9]/td/table/tbody/tr[1]/td[2]"
+const valueXPath = fichaFieldsetXPath + "/div/table/tbody/tr[9]/td/table/tbody/tr[3]/td[2]/span[1]"
+const currencyXPath = fichaFieldsetXPath + "/div/table/tbody/tr[9]/td/table/tbody/tr[3]/td[2]/span[2]"
+const labelXPathFormat = ".//td[normalize-space(.)='%s:' or normalize-space(.)='%s']/following-sibling::td[1]"
+const itemsElement = "tbFicha:idGridLstItems"
+const itemsContentElement = itemsElement + "_content"
+const itemsPaginatorNextSelector = "[id^='" + itemsElement + "_paginator'] " + nextPageButton
+const itemCellSelector = ".ui-datagrid-column"
+const participantsTableSelector = "[id$=':dtParticipantes']"
+const participantsDataSelector = "[id$=':dtParticipantes_data']"
+
+var rucPattern = regexp.MustCompile(`\b(10|15|17|20)\d{9}\b`)
+
+func extractData(driver selenium.WebDriver, id int) (record.Record, error) {
 	stderr := log.New(os.Stderr, "[extractor-datos] ", 0)
 
 	nomenclature, err := extractTextByXPath(driver, nomenclatureXPath)
 	if err != nil {
-		return fmt.Errorf("error al extraer la nomenclatura:\n%s", err)
+		return record.Record{}, fmt.Errorf("error al extraer la nomenclatura:\n%s", err)
 	}
 	entity, err := extractTextByXPath(driver, entityXPath)
 	if err != nil {
-		return fmt.Errorf("error al extraer la entidad:\n%s", err)
+		return record.Record{}, fmt.Errorf("error al extraer la entidad:\n%s", err)
 	}
 	objectType, err := extractTextByXPath(driver, objectTypeXPath)
 	if err != nil {
-		return fmt.Errorf("error al extraer el tipo de objeto:\n%s", err)
+		return record.Record{}, fmt.Errorf("error al extraer el tipo de objeto:\n%s", err)
 	}
 	value, err := extractTextByXPath(driver, valueXPath)
 	if err != nil {
-		return fmt.Errorf("error al extraer el valor:\n%s", err)
+		return record.Record{}, fmt.Errorf("error al extraer el valor:\n%s", err)
 	}
 	currency, err := extractTextByXPath(driver, currencyXPath)
 	if err != nil {
-		return fmt.Errorf("error al extraer la moneda:\n%s", err)
+		return record.Record{}, fmt.Errorf("error al extraer la moneda:\n%s", err)
+	}
+
+	location, err := extractEntityLocation(driver)
+	if err != nil {
+		return record.Record{}, fmt.Errorf("error al extraer los datos de la entidad:\n%s", err)
 	}
 
-	description, err := extractDescription(driver)
+	schedule, err := extractSchedule(driver, stderr)
 	if err != nil {
-		return fmt.Errorf("error al extraer la descripción:\n%s", err)
+		return record.Record{}, fmt.Errorf("error al extraer el cronograma:\n%s", err)
 	}
-	hasWinner, winnerData, err := extractWinner(driver)
+	documents, err := extractDocuments(driver, stderr)
+	if err != nil {
+		return record.Record{}, fmt.Errorf("error al extraer los documentos:\n%s", err)
+	}
+	items, err := extractItems(driver, stderr)
+	if err != nil {
+		return record.Record{}, fmt.Errorf("error al extraer los items:\n%s", err)
+	}
+
+	fillAwardDates(items, schedule)
 
-	if hasWinner {
-		winnerName := winnerData[0]
-		mype := winnerData[1]
-		jungle := winnerData[2]
+	permalink, processID := extractPermalink(driver)
 
-		stderr.Printf("El proceso con id %d y descripción %s tiene un ganador\n", id+1, description)
-		stdout.Printf(printTemplate,
-			fmt.Sprintf("%d", id+1),
-			entity,
-			nomenclature,
-			objectType,
-			description,
-			value,
-			currency,
-			winnerName,
-			mype,
-			jungle,
-		)
-	} else {
-		stderr.Printf("El proceso con id %d y descripción %s no tiene ganador\n", id+1, description)
+	data := record.Record{
+		ID:           id + 1,
+		ProcessID:    processID,
+		Permalink:    permalink,
+		Entity:       entity,
+		EntityRUC:    location["ruc"],
+		Department:   location["departamento"],
+		Province:     location["provincia"],
+		District:     location["distrito"],
+		Ubigeo:       location["ubigeo"],
+		Nomenclature: nomenclature,
+		ObjectType:   objectType,
+		Value:        value,
+		Currency:     currency,
+		Items:        items,
+		Schedule:     schedule,
+		Documents:    documents,
 	}
 
-	return nil
+	data.Key = data.StableKey()
+	data.NormalizeValue()
+	if data.ValueError != "" {
+		stderr.Printf("El proceso con id %d tiene un valor que no se pudo interpretar: %s\n", id+1, data.ValueError)
+	}
+
+	for _, item := range items {
+		for _, participant := range item.Participants {
+			for _, problem := range participant.FlagErrors {
+				stderr.Printf("El postor %s del item %d del proceso con id %d no se pudo normalizar, %s\n", participant.Name, item.Number, id+1, problem)
+			}
+		}
+		if _, hasWinner := item.Winner(); hasWinner {
+			stderr.Printf("El item %d del proceso con id %d y descripción %s tiene un ganador\n", item.Number, id+1, item.Description)
+		} else {
+			stderr.Printf("El item %d del proceso con id %d y descripción %s no tiene ganador (%s)\n", item.Number, id+1, item.Description, item.State)
+		}
+	}
+
+	return data, nil
+}
+
+// extractEntityLocation busca el RUC y la ubicación de la entidad dentro del
+// fieldset del que se lee la entidad, para no tomar los del contratista o del
+// lugar de entrega. Los datos que la ficha no muestra quedan vacíos.
+func extractEntityLocation(driver selenium.WebDriver) (map[string]string, error) {
+	labels := map[string]string{
+		"ruc":          "RUC",
+		"departamento": "Departamento",
+		"provincia":    "Provincia",
+		"distrito":     "Distrito",
+		"ubigeo":       "Ubigeo",
+	}
+
+	fieldset, err := driver.FindElement(selenium.ByXPATH, fichaFieldsetXPath)
+	if err != nil {
+		return nil, err
+	}
+
+	location := map[string]string{}
+	for key, label := range labels {
+		value, err := extractFieldByLabel(fieldset, label)
+		if err != nil {
+			return nil, err
+		}
+		location[key] = value
+	}
+
+	return location, nil
+}
+
+// extractFieldByLabel devuelve el valor de la celda que sigue a la etiqueta
+// dentro de container. Si la etiqueta aparece más de una vez devuelve un error
+// en lugar de elegir una.
+func extractFieldByLabel(container selenium.WebElement, label string) (string, error) {
+	xpath := fmt.Sprintf(labelXPathFormat, label, label)
+	elements, err := container.FindElements(selenium.ByXPATH, xpath)
+	if err != nil {
+		return "", err
+	}
+	if len(elements) == 0 {
+		return "", nil
+	}
+	if len(elements) > 1 {
+		return "", fmt.Errorf("la etiqueta %s aparece %d veces en los datos de la entidad", label, len(elements))
+	}
+
+	text, err := elements[0].Text()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(text), nil
 }
 
 func extractTextByXPath(driver selenium.WebDriver, xpath string) (string, error) {
@@ -125,37 +204,241 @@ func extractObjectType(driver selenium.WebDriver) (string, error) {
 	return text, nil
 }
 
-func extractDescription(driver selenium.WebDriver) (string, error) {
+func expandItems(driver selenium.WebDriver) error {
 	legends, err := driver.FindElements(selenium.ByTagName, "legend")
 	if err != nil {
-		return "", err
+		return err
 	}
 
 	for _, legend := range legends {
 		text, err := legend.Text()
 		if err != nil {
-			return "", err
+			return err
 		}
 		if !strings.Contains(text, "Ver listado") {
 			continue
 		}
 
-		err = legend.Click()
+		err = click(driver, legend, pageAction, ajaxIdle)
 		if err != nil {
-			return "", err
+			return err
 		}
 
 		break
 	}
 
 	time.Sleep(1 * time.Second)
+	return nil
+}
+
+// extractItems lee los items de todas las páginas del listado de items de la
+// ficha, cada uno con los postores de su propia celda.
+func extractItems(driver selenium.WebDriver, logger *log.Logger) ([]record.Item, error) {
+	if err := expandItems(driver); err != nil {
+		return nil, err
+	}
+
+	items := []record.Item{}
+	for page := 1; ; page++ {
+		content, err := driver.FindElement(selenium.ByID, itemsContentElement)
+		if err != nil {
+			return nil, err
+		}
+
+		cells, err := content.FindElements(selenium.ByCSSSelector, itemCellSelector)
+		if err != nil {
+			return nil, err
+		}
+		if len(cells) == 0 {
+			cells = []selenium.WebElement{content}
+		}
+
+		for _, cell := range cells {
+			item, err := extractItem(driver, cell, len(items), logger)
+			if err != nil {
+				return nil, fmt.Errorf("error al extraer el item %d:\n%s", len(items)+1, err)
+			}
+			items = append(items, item)
+		}
+
+		next, err := nextItemsPage(driver)
+		if err != nil {
+			return nil, fmt.Errorf("error al obtener la página %d de los items:\n%s", page+1, err)
+		}
+		if next == nil {
+			return items, nil
+		}
+		if err := click(driver, next, pageAction, ajaxIdle); err != nil {
+			return nil, fmt.Errorf("error al ir a la página %d de los items:\n%s", page+1, err)
+		}
+	}
+}
 
-	table, err := driver.FindElement(selenium.ByID, "tbFicha:idGridLstItems_content")
+// nextItemsPage devuelve el botón de siguiente página del listado de items, o
+// nil si el listado no tiene paginador o ya está en la última página. Solo se
+// busca en el paginador del listado y no en los de las tablas de postores.
+func nextItemsPage(driver selenium.WebDriver) (selenium.WebElement, error) {
+	buttons, err := driver.FindElements(selenium.ByCSSSelector, itemsPaginatorNextSelector)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if len(buttons) == 0 {
+		return nil, nil
+	}
+
+	classNames, err := buttons[0].GetAttribute("class")
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(classNames, "ui-state-disabled") {
+		return nil, nil
+	}
+	return buttons[0], nil
+}
+
+func extractItem(driver selenium.WebDriver, cell selenium.WebElement, index int, logger *log.Logger) (record.Item, error) {
+	item := record.Item{Number: index + 1}
+
+	fields, err := extractLabeledValues(cell)
+	if err != nil {
+		return item, err
+	}
+	for _, field := range fields {
+		assignItemField(&item, field.Header, field.Value)
 	}
 
-	information, err := table.FindElements(selenium.ByTagName, "span")
+	if item.Description == "" {
+		description, err := extractFirstSpan(cell)
+		if err != nil {
+			return item, err
+		}
+		item.Description = description
+	}
+
+	participants, participantRows, err := extractParticipants(cell)
+	if err != nil {
+		return item, fmt.Errorf("error al extraer los postores:\n%s", err)
+	}
+	item.Participants = participants
+
+	for i := range item.Participants {
+		if !item.Participants[i].Consortium {
+			continue
+		}
+		members, err := extractConsortiumMembers(driver, participantRows[i], item.Participants[i])
+		if err != nil {
+			logger.Printf("No se pudo extraer los integrantes del consorcio %s:\n%v\n", item.Participants[i].Name, err)
+			members = []record.Member{}
+		}
+		item.Participants[i].Members = members
+	}
+
+	if err := completeAward(&item); err != nil {
+		logger.Printf("No se pudo analizar la fecha de buena pro del item %d: %s\n", item.Number, err)
+	}
+
+	return item, nil
+}
+
+// completeAward normaliza el estado del item y completa la adjudicación con
+// los datos del ganador: el monto adjudicado del item es, si la ficha no lo
+// muestra, el adjudicado del ganador o, si tampoco, su oferta. La fecha de
+// buena pro del item se interpreta en la hora de Lima; si no se puede, el
+// item queda sin fecha y se devuelve el error.
+func completeAward(item *record.Item) error {
+	winner, hasWinner := item.Winner()
+	item.State = record.NormalizeState(item.Status, hasWinner)
+
+	if hasWinner && item.AwardedAmount == "" {
+		item.AwardedAmount = winner.AwardedAmount
+		if item.AwardedAmount == "" {
+			item.AwardedAmount = winner.Amount
+		}
+	}
+
+	var err error
+	item.AwardDate, err = parsePortalTime(item.AwardDateRaw)
+	return err
+}
+
+// fillAwardDates usa la etapa de buena pro del cronograma como fecha de buena
+// pro de los items con ganador que no muestran la suya.
+func fillAwardDates(items []record.Item, schedule []record.Stage) {
+	awardDate := findAwardDate(schedule)
+	for i := range items {
+		if _, hasWinner := items[i].Winner(); hasWinner && items[i].AwardDate == nil {
+			items[i].AwardDate = awardDate
+		}
+	}
+}
+
+// extractLabeledValues lee las celdas de la forma "Etiqueta:" seguidas de su
+// valor, que es como el portal muestra los datos de la ficha.
+func extractLabeledValues(element selenium.WebElement) ([]record.Field, error) {
+	rows, err := element.FindElements(selenium.ByTagName, "tr")
+	if err != nil {
+		return nil, err
+	}
+
+	fields := []record.Field{}
+	for _, row := range rows {
+		columns, err := row.FindElements(selenium.ByXPATH, "./td")
+		if err != nil {
+			return nil, err
+		}
+
+		for i := 0; i+1 < len(columns); i++ {
+			label, err := columns[i].Text()
+			if err != nil {
+				return nil, err
+			}
+			label = strings.TrimSpace(label)
+			if !strings.HasSuffix(label, ":") {
+				continue
+			}
+
+			value, err := columns[i+1].Text()
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, record.Field{
+				Header: strings.TrimSpace(strings.TrimSuffix(label, ":")),
+				Value:  strings.TrimSpace(value),
+			})
+			i++
+		}
+	}
+
+	return fields, nil
+}
+
+func assignItemField(item *record.Item, label string, value string) {
+	normalized := record.Normalize(label)
+
+	switch {
+	case strings.Contains(normalized, "fecha") && strings.Contains(normalized, "buena pro"):
+		item.AwardDateRaw = value
+	case strings.Contains(normalized, "monto adjudicado"), strings.Contains(normalized, "valor adjudicado"):
+		item.AwardedAmount = value
+	case strings.Contains(normalized, "nro") && strings.Contains(normalized, "item"):
+		if number, err := strconv.Atoi(value); err == nil {
+			item.Number = number
+		}
+	case strings.Contains(normalized, "descripcion"):
+		item.Description = value
+	case strings.Contains(normalized, "cantidad"):
+		item.Quantity = value
+	case strings.Contains(normalized, "unidad"):
+		item.Unit = value
+	case strings.Contains(normalized, "valor"):
+		item.ReferenceValue = value
+	case strings.Contains(normalized, "estado"):
+		item.Status = value
+	}
+}
+
+func extractFirstSpan(element selenium.WebElement) (string, error) {
+	information, err := element.FindElements(selenium.ByTagName, "span")
 	if err != nil {
 		return "", err
 	}
@@ -164,14 +447,12 @@ func extractDescription(driver selenium.WebDriver) (string, error) {
 		return "", fmt.Errorf("no description found")
 	}
 
-	span := information[0]
-	description, err := span.Text()
-
+	description, err := information[0].Text()
 	if err != nil {
 		return "", err
 	}
 
-	return description, nil
+	return strings.TrimSpace(description), nil
 }
 
 func extractValue(driver selenium.WebDriver) (string, error) {
@@ -196,30 +477,121 @@ func extractValue(driver selenium.WebDriver) (string, error) {
 	return text, nil
 }
 
-func extractWinner(driver selenium.WebDriver) (bool, []string, error) {
-	winnerTable, err := driver.FindElement(selenium.ByID, winnerElement)
+// extractParticipants lee la tabla de postores de la celda del item. Los
+// procesos en convocatoria o evaluación no la tienen y se devuelve una lista
+// vacía.
+func extractParticipants(cell selenium.WebElement) ([]record.Participant, []selenium.WebElement, error) {
+	tables, err := cell.FindElements(selenium.ByCSSSelector, participantsTableSelector)
 	if err != nil {
-		return false, nil, err
+		return nil, nil, err
 	}
-	columns, err := winnerTable.FindElements(selenium.ByTagName, "td")
-	if len(columns) == 1 {
-		return false, nil, nil
+	if len(tables) == 0 {
+		return []record.Participant{}, []selenium.WebElement{}, nil
 	}
-
-	winner, err := columns[0].Text()
+	headers, err := extractHeaders(tables[0])
 	if err != nil {
-		return false, nil, err
+		return nil, nil, err
 	}
 
-	mype, err := columns[1].Text()
+	participantsData, err := tables[0].FindElement(selenium.ByCSSSelector, participantsDataSelector)
 	if err != nil {
-		return false, nil, err
+		return nil, nil, err
+	}
+	rows, err := participantsData.FindElements(selenium.ByTagName, "tr")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	participants := []record.Participant{}
+	participantRows := []selenium.WebElement{}
+	for _, row := range rows {
+		columns, err := row.FindElements(selenium.ByTagName, "td")
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(columns) <= 1 {
+			continue
+		}
+
+		participant := record.Participant{}
+		for i, column := range columns {
+			text, err := column.Text()
+			if err != nil {
+				return nil, nil, err
+			}
+			text = strings.TrimSpace(text)
+
+			header := participantHeader(headers, i)
+			participant.Columns = append(participant.Columns, record.Field{Header: header, Value: text})
+			assignParticipantField(&participant, header, text)
+		}
+		if participant.RUC == "" {
+			participant.RUC = rucPattern.FindString(participant.Name)
+		}
+		participant.Consortium = record.IsConsortium(participant.Name)
+		participant.NormalizeFlags()
+		participants = append(participants, participant)
+		participantRows = append(participantRows, row)
 	}
 
-	jungle, err := columns[2].Text()
+	return participants, participantRows, nil
+}
+
+func extractHeaders(table selenium.WebElement) ([]string, error) {
+	cells, err := table.FindElements(selenium.ByCSSSelector, "thead th")
 	if err != nil {
-		return false, nil, err
+		return nil, err
+	}
+
+	headers := make([]string, 0, len(cells))
+	for _, cell := range cells {
+		text, err := cell.Text()
+		if err != nil {
+			return nil, err
+		}
+		headers = append(headers, strings.TrimSpace(text))
 	}
 
-	return true, []string{winner, mype, jungle}, nil
+	return headers, nil
+}
+
+// participantHeader devuelve la cabecera de la columna; si el portal no la
+// muestra se usa el orden conocido de postor, MYPE y selva.
+func participantHeader(headers []string, index int) string {
+	if index < len(headers) && headers[index] != "" {
+		return headers[index]
+	}
+
+	switch index {
+	case 0:
+		return "Postor"
+	case 1:
+		return "Es MYPE"
+	case 2:
+		return "Es Selva"
+	}
+	return fmt.Sprintf("Columna %d", index+1)
+}
+
+func assignParticipantField(participant *record.Participant, header string, value string) {
+	normalized := record.Normalize(header)
+
+	switch {
+	case strings.Contains(normalized, "ruc"):
+		participant.RUC = value
+	case strings.Contains(normalized, "mype"):
+		participant.MYPE = value
+	case strings.Contains(normalized, "selva"), strings.Contains(normalized, "amazon"):
+		participant.Selva = value
+	case strings.Contains(normalized, "puntaje"):
+		participant.Score = value
+	case strings.Contains(normalized, "monto adjudicado"), strings.Contains(normalized, "valor adjudicado"):
+		participant.AwardedAmount = value
+	case strings.Contains(normalized, "monto"), strings.Contains(normalized, "oferta"), strings.Contains(normalized, "precio"):
+		participant.Amount = value
+	case strings.Contains(normalized, "buena pro"), strings.Contains(normalized, "adjudic"), strings.Contains(normalized, "ganador"):
+		participant.Awarded = value
+	case strings.Contains(normalized, "postor"), strings.Contains(normalized, "nombre"), strings.Contains(normalized, "razon social"), strings.Contains(normalized, "participante"):
+		participant.Name = value
+	}
 }
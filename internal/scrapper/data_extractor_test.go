@@ -0,0 +1,194 @@
+package scrapper
+
+import (
+	"dieg0407/seace/internal/record"
+	"testing"
+	"time"
+)
+
+func TestAssignItemField(t *testing.T) {
+	tests := []struct {
+		label string
+		value string
+		want  record.Item
+	}{
+		{label: "Nro. Item", value: "3", want: record.Item{Number: 3}},
+		{label: "Nro. Ítem", value: "tres", want: record.Item{}},
+		{label: "Descripción del Ítem", value: "Laptops", want: record.Item{Description: "Laptops"}},
+		{label: "Cantidad", value: "10", want: record.Item{Quantity: "10"}},
+		{label: "Unidad de Medida", value: "UNIDAD", want: record.Item{Unit: "UNIDAD"}},
+		{label: "Valor Referencial", value: "1,000.00", want: record.Item{ReferenceValue: "1,000.00"}},
+		{label: "Valor Estimado", value: "900.00", want: record.Item{ReferenceValue: "900.00"}},
+		{label: "Monto Adjudicado", value: "950.00", want: record.Item{AwardedAmount: "950.00"}},
+		{label: "Valor Adjudicado", value: "950.00", want: record.Item{AwardedAmount: "950.00"}},
+		{label: "Fecha de Buena Pro", value: "15/11/2024", want: record.Item{AwardDateRaw: "15/11/2024"}},
+		{label: "Estado", value: "Adjudicado", want: record.Item{Status: "Adjudicado"}},
+		{label: "ESTADO DEL ÍTEM", value: "Desierto", want: record.Item{Status: "Desierto"}},
+		{label: "Paquete", value: "1", want: record.Item{}},
+	}
+
+	for _, test := range tests {
+		item := record.Item{}
+		assignItemField(&item, test.label, test.value)
+		if item.Number != test.want.Number || item.Description != test.want.Description || item.Quantity != test.want.Quantity ||
+			item.Unit != test.want.Unit || item.ReferenceValue != test.want.ReferenceValue || item.AwardedAmount != test.want.AwardedAmount ||
+			item.AwardDateRaw != test.want.AwardDateRaw || item.Status != test.want.Status {
+			t.Errorf("assignItemField(%q, %q) = %+v, se esperaba %+v", test.label, test.value, item, test.want)
+		}
+	}
+}
+
+func TestAssignParticipantField(t *testing.T) {
+	tests := []struct {
+		header string
+		value  string
+		want   record.Participant
+	}{
+		{header: "Postor", value: "EMPRESA SAC", want: record.Participant{Name: "EMPRESA SAC"}},
+		{header: "Nombre o Razón Social", value: "EMPRESA SAC", want: record.Participant{Name: "EMPRESA SAC"}},
+		{header: "RUC del Postor", value: "20123456789", want: record.Participant{RUC: "20123456789"}},
+		{header: "Es MYPE", value: "SI", want: record.Participant{MYPE: "SI"}},
+		{header: "Ley de la Amazonía", value: "NO", want: record.Participant{Selva: "NO"}},
+		{header: "Es Selva", value: "NO", want: record.Participant{Selva: "NO"}},
+		{header: "Puntaje Total", value: "95.5", want: record.Participant{Score: "95.5"}},
+		{header: "Monto Adjudicado", value: "950.00", want: record.Participant{AwardedAmount: "950.00"}},
+		{header: "Monto Ofertado", value: "980.00", want: record.Participant{Amount: "980.00"}},
+		{header: "Precio de la Oferta", value: "980.00", want: record.Participant{Amount: "980.00"}},
+		{header: "Buena Pro", value: "SI", want: record.Participant{Awarded: "SI"}},
+		{header: "Ganador", value: "X", want: record.Participant{Awarded: "X"}},
+		{header: "Columna 7", value: "otro", want: record.Participant{}},
+	}
+
+	for _, test := range tests {
+		participant := record.Participant{}
+		assignParticipantField(&participant, test.header, test.value)
+		if participant.Name != test.want.Name || participant.RUC != test.want.RUC || participant.MYPE != test.want.MYPE ||
+			participant.Selva != test.want.Selva || participant.Score != test.want.Score || participant.Amount != test.want.Amount ||
+			participant.AwardedAmount != test.want.AwardedAmount || participant.Awarded != test.want.Awarded {
+			t.Errorf("assignParticipantField(%q, %q) = %+v, se esperaba %+v", test.header, test.value, participant, test.want)
+		}
+	}
+}
+
+func TestParticipantHeader(t *testing.T) {
+	headers := []string{"Postor", "", "Es Selva"}
+
+	tests := []struct {
+		headers []string
+		index   int
+		want    string
+	}{
+		{headers: headers, index: 0, want: "Postor"},
+		{headers: headers, index: 1, want: "Es MYPE"},
+		{headers: headers, index: 2, want: "Es Selva"},
+		{headers: headers, index: 3, want: "Columna 4"},
+		{headers: nil, index: 0, want: "Postor"},
+		{headers: nil, index: 2, want: "Es Selva"},
+	}
+
+	for _, test := range tests {
+		if got := participantHeader(test.headers, test.index); got != test.want {
+			t.Errorf("participantHeader(%q, %d) = %q, se esperaba %q", test.headers, test.index, got, test.want)
+		}
+	}
+}
+
+func TestCompleteAward(t *testing.T) {
+	awarded := record.Participant{Name: "GANADOR SAC", IsAwarded: record.FlagYes, AwardedAmount: "950.00", Amount: "980.00"}
+	offered := record.Participant{Name: "GANADOR SAC", IsAwarded: record.FlagYes, Amount: "980.00"}
+	loser := record.Participant{Name: "PERDEDOR SAC", IsAwarded: record.FlagNo, AwardedAmount: "990.00"}
+
+	tests := []struct {
+		name       string
+		item       record.Item
+		wantAmount string
+		wantState  string
+		wantDate   string
+		wantErr    bool
+	}{
+		{
+			name:       "monto del item",
+			item:       record.Item{AwardedAmount: "900.00", Participants: []record.Participant{awarded}},
+			wantAmount: "900.00",
+			wantState:  record.StateAwarded,
+		},
+		{
+			name:       "monto adjudicado del ganador",
+			item:       record.Item{Participants: []record.Participant{loser, awarded}},
+			wantAmount: "950.00",
+			wantState:  record.StateAwarded,
+		},
+		{
+			name:       "oferta del ganador",
+			item:       record.Item{Participants: []record.Participant{offered}},
+			wantAmount: "980.00",
+			wantState:  record.StateAwarded,
+		},
+		{
+			name:      "sin ganador",
+			item:      record.Item{Status: "Desierto", Participants: []record.Participant{loser}},
+			wantState: record.StateDeserted,
+		},
+		{
+			name:       "fecha de buena pro",
+			item:       record.Item{AwardDateRaw: "15/11/2024 10:30", Participants: []record.Participant{awarded}},
+			wantAmount: "950.00",
+			wantState:  record.StateAwarded,
+			wantDate:   "2024-11-15T10:30:00-05:00",
+		},
+		{
+			name:       "fecha de buena pro inválida",
+			item:       record.Item{AwardDateRaw: "por definir", Participants: []record.Participant{awarded}},
+			wantAmount: "950.00",
+			wantState:  record.StateAwarded,
+			wantErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			item := test.item
+			err := completeAward(&item)
+			if (err != nil) != test.wantErr {
+				t.Errorf("completeAward() error = %v, se esperaba error: %t", err, test.wantErr)
+			}
+			if item.AwardedAmount != test.wantAmount {
+				t.Errorf("completeAward() monto = %q, se esperaba %q", item.AwardedAmount, test.wantAmount)
+			}
+			if item.State != test.wantState {
+				t.Errorf("completeAward() estado = %q, se esperaba %q", item.State, test.wantState)
+			}
+			if got := formatDate(item.AwardDate); got != test.wantDate {
+				t.Errorf("completeAward() fecha = %q, se esperaba %q", got, test.wantDate)
+			}
+		})
+	}
+}
+
+func TestFillAwardDates(t *testing.T) {
+	start, _ := parsePortalTime("10/03/2024 09:00")
+	own, _ := parsePortalTime("12/03/2024 11:00")
+	schedule := []record.Stage{{Name: "Otorgamiento de la Buena Pro", Start: start}}
+	winner := []record.Participant{{Name: "GANADOR SAC", IsAwarded: record.FlagYes}}
+
+	items := []record.Item{
+		{Number: 1, Participants: winner},
+		{Number: 2, Participants: winner, AwardDate: own},
+		{Number: 3},
+	}
+	fillAwardDates(items, schedule)
+
+	want := []string{"2024-03-10T09:00:00-05:00", "2024-03-12T11:00:00-05:00", ""}
+	for i, item := range items {
+		if got := formatDate(item.AwardDate); got != want[i] {
+			t.Errorf("fillAwardDates() item %d = %q, se esperaba %q", item.Number, got, want[i])
+		}
+	}
+}
+
+func formatDate(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return value.Format(time.RFC3339)
+}
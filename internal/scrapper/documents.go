@@ -0,0 +1,268 @@
+package scrapper
+
+import (
+	"crypto/sha256"
+	"dieg0407/seace/internal/record"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tebeka/selenium"
+)
+
+const documentsElement = "tbFicha:dtDocumentos"
+const documentsManifest = "manifest.json"
+const downloadTimeout = 2 * time.Minute
+
+var unsafeFileCharacters = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// extractDocuments lista los documentos publicados en la ficha. El listado es
+// auxiliar: si la ficha no tiene la tabla se devuelve una lista vacía.
+func extractDocuments(driver selenium.WebDriver, logger *log.Logger) ([]record.Document, error) {
+	tables, err := driver.FindElements(selenium.ByID, documentsElement)
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		logger.Println("La ficha no tiene documentos publicados")
+		return []record.Document{}, nil
+	}
+	headers, err := extractHeaders(tables[0])
+	if err != nil {
+		return nil, err
+	}
+
+	documentsData, err := driver.FindElement(selenium.ByID, documentsElement+"_data")
+	if err != nil {
+		return nil, err
+	}
+	rows, err := documentsData.FindElements(selenium.ByTagName, "tr")
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := driver.CurrentURL()
+	if err != nil {
+		return nil, err
+	}
+
+	documents := []record.Document{}
+	for _, row := range rows {
+		columns, err := row.FindElements(selenium.ByTagName, "td")
+		if err != nil {
+			return nil, err
+		}
+		if len(columns) <= 1 {
+			continue
+		}
+
+		document := record.Document{}
+		for i, column := range columns {
+			if i >= len(headers) {
+				break
+			}
+			text, err := column.Text()
+			if err != nil {
+				return nil, err
+			}
+			if err := assignDocumentField(&document, headers[i], strings.TrimSpace(text)); err != nil {
+				logger.Printf("%v\n", err)
+			}
+		}
+
+		links, err := row.FindElements(selenium.ByTagName, "a")
+		if err != nil {
+			return nil, err
+		}
+		for _, link := range links {
+			href, err := link.GetAttribute("href")
+			if err != nil || !isDownloadURL(href, current) {
+				continue
+			}
+			document.URL = href
+			break
+		}
+		if document.URL == "" && len(links) > 0 {
+			logger.Printf("El documento %s solo se descarga con un enlace de la página (href=\"#\"), queda sin enlace y no se descargará\n", document.Name)
+		}
+
+		documents = append(documents, document)
+	}
+
+	return documents, nil
+}
+
+func assignDocumentField(document *record.Document, header string, value string) error {
+	normalized := record.Normalize(header)
+
+	switch {
+	case strings.Contains(normalized, "etapa"):
+		document.Stage = value
+	case strings.Contains(normalized, "fecha"):
+		document.PublishedRaw = value
+		published, err := parsePortalTime(value)
+		if err != nil {
+			return fmt.Errorf("no se pudo analizar la fecha de publicación del documento: %s", err)
+		}
+		document.Published = published
+	case strings.Contains(normalized, "documento"), strings.Contains(normalized, "nombre"):
+		document.Name = value
+	}
+	return nil
+}
+
+// isDownloadURL descarta los enlaces que no son http y los que apuntan a la
+// misma página, como los href="#" de JSF que el navegador resuelve contra la
+// url actual. Esos enlaces envían el formulario de la ficha y el cliente http
+// de la descarga no los puede seguir.
+func isDownloadURL(href string, current string) bool {
+	parsed, err := neturl.Parse(href)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return false
+	}
+	if parsed.Fragment != "" || strings.HasSuffix(href, "#") {
+		return false
+	}
+
+	page, err := neturl.Parse(current)
+	if err != nil {
+		return true
+	}
+	page.Fragment = ""
+	return parsed.String() != page.String()
+}
+
+// downloadDocuments guarda los documentos del proceso en su propia carpeta con
+// un cliente http que lleva las cookies de la sesión del navegador, y deja un
+// manifiesto con el SHA-256 de cada archivo. Los documentos sin enlace quedan
+// en el manifiesto sin archivo.
+func downloadDocuments(driver selenium.WebDriver, data *record.Record, directory string, logger *log.Logger) error {
+	if len(data.Documents) == 0 {
+		return nil
+	}
+
+	folder, err := documentsFolder(directory, *data)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		return fmt.Errorf("error al crear el directorio de documentos:\n%w", err)
+	}
+
+	cookies, err := driver.GetCookies()
+	if err != nil {
+		return fmt.Errorf("error al obtener las cookies del navegador:\n%w", err)
+	}
+	client := &http.Client{Timeout: downloadTimeout}
+
+	for i := range data.Documents {
+		document := &data.Documents[i]
+		if document.URL == "" {
+			logger.Printf("El documento %s no tiene enlace de descarga, no se descarga\n", document.Name)
+			continue
+		}
+
+		fileName := fmt.Sprintf("%02d_%s", i+1, safeFileName(documentFileName(*document)))
+		var checksum string
+		err := perform(driver, pageAction, func() (err error) {
+			checksum, err = downloadFile(client, cookies, document.URL, filepath.Join(folder, fileName))
+			return err
+		}, nil)
+		if err != nil {
+			logger.Printf("Error al descargar el documento %s:\n%v", document.Name, err)
+			continue
+		}
+
+		document.File = filepath.Join(folder, fileName)
+		document.SHA256 = checksum
+	}
+
+	manifest, err := json.MarshalIndent(data.Documents, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error al generar el manifiesto de documentos:\n%w", err)
+	}
+	if err := os.WriteFile(filepath.Join(folder, documentsManifest), manifest, 0644); err != nil {
+		return fmt.Errorf("error al guardar el manifiesto de documentos:\n%w", err)
+	}
+
+	return nil
+}
+
+func downloadFile(client *http.Client, cookies []selenium.Cookie, url string, destination string) (string, error) {
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	for _, cookie := range cookies {
+		request.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("respuesta inesperada del portal: %s", response.Status)
+	}
+
+	file, err := os.Create(destination)
+	if err != nil {
+		return "", err
+	}
+
+	// Un archivo a medias no debe quedar como si fuera el documento
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(file, hash), response.Body); err != nil {
+		file.Close()
+		os.Remove(destination)
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(destination)
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// documentsFolder es la carpeta del proceso dentro del directorio de
+// documentos. Sin nomenclatura se usa la clave del proceso, para no escribir
+// nunca en el directorio mismo ni fuera de él.
+func documentsFolder(directory string, data record.Record) (string, error) {
+	for _, name := range []string{data.Nomenclature, data.Key} {
+		if folder := safeFileName(name); folder != "" {
+			return filepath.Join(directory, folder), nil
+		}
+	}
+	return "", fmt.Errorf("el proceso no tiene nomenclatura ni clave para nombrar la carpeta de sus documentos")
+}
+
+func documentFileName(document record.Document) string {
+	name := path.Base(strings.SplitN(document.URL, "?", 2)[0])
+	if path.Ext(name) != "" {
+		return name
+	}
+	if document.Name != "" {
+		return document.Name
+	}
+	return "documento"
+}
+
+// safeFileName deja solo letras, números, puntos, guiones y guiones bajos. Los
+// puntos de los extremos también se quitan para que "." o ".." no se salgan
+// de la carpeta.
+func safeFileName(name string) string {
+	return strings.Trim(unsafeFileCharacters.ReplaceAllString(name, "_"), "_.")
+}
@@ -0,0 +1,95 @@
+package scrapper
+
+import (
+	"dieg0407/seace/internal/record"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsDownloadURL(t *testing.T) {
+	const current = "https://prod2.seace.gob.pe/seacebus-uiwd-pub/fichaSeleccion/fichaSeleccion.xhtml?id=1"
+
+	tests := []struct {
+		href string
+		want bool
+	}{
+		{href: "https://prod2.seace.gob.pe/SeaceWeb-PRO/SdescargarArchivoAlfresco?fileCode=abc", want: true},
+		{href: "http://example.com/bases.pdf", want: true},
+		{href: "", want: false},
+		{href: "#", want: false},
+		{href: current + "#", want: false},
+		{href: current + "#documentos", want: false},
+		{href: current, want: false},
+		{href: "javascript:void(0)", want: false},
+		{href: "mailto:mesa@seace.gob.pe", want: false},
+		{href: "ftp://example.com/bases.pdf", want: false},
+	}
+
+	for _, test := range tests {
+		if got := isDownloadURL(test.href, current); got != test.want {
+			t.Errorf("isDownloadURL(%q) = %t, se esperaba %t", test.href, got, test.want)
+		}
+	}
+}
+
+func TestSafeFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "AS-SM-12-2024-MPL-1", want: "AS-SM-12-2024-MPL-1"},
+		{name: "Bases Integradas (v2).pdf", want: "Bases_Integradas_v2_.pdf"},
+		{name: "Acta de otorgamiento de la buena pro", want: "Acta_de_otorgamiento_de_la_buena_pro"},
+		{name: "../../etc/passwd", want: "etc_passwd"},
+		{name: "..", want: ""},
+		{name: ".", want: ""},
+		{name: "  ", want: ""},
+		{name: "", want: ""},
+	}
+
+	for _, test := range tests {
+		if got := safeFileName(test.name); got != test.want {
+			t.Errorf("safeFileName(%q) = %q, se esperaba %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestDocumentsFolder(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    record.Record
+		want    string
+		wantErr bool
+	}{
+		{name: "nomenclatura", data: record.Record{Nomenclature: "AS-SM-12-2024-MPL-1", Key: "seace:1"}, want: filepath.Join("docs", "AS-SM-12-2024-MPL-1")},
+		{name: "sin nomenclatura usa la clave", data: record.Record{Key: "seace:1"}, want: filepath.Join("docs", "seace_1")},
+		{name: "nomenclatura sin caracteres válidos", data: record.Record{Nomenclature: "..", Key: "seace:1"}, want: filepath.Join("docs", "seace_1")},
+		{name: "sin nomenclatura ni clave", data: record.Record{}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := documentsFolder("docs", test.data)
+			if (err != nil) != test.wantErr || got != test.want {
+				t.Errorf("documentsFolder() = %q, %v, se esperaba %q con error: %t", got, err, test.want, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestDocumentFileName(t *testing.T) {
+	tests := []struct {
+		document record.Document
+		want     string
+	}{
+		{document: record.Document{Name: "Bases", URL: "https://example.com/archivos/bases.pdf?version=2"}, want: "bases.pdf"},
+		{document: record.Document{Name: "Bases", URL: "https://example.com/SdescargarArchivoAlfresco?fileCode=abc"}, want: "Bases"},
+		{document: record.Document{URL: "https://example.com/descargar"}, want: "documento"},
+	}
+
+	for _, test := range tests {
+		if got := documentFileName(test.document); got != test.want {
+			t.Errorf("documentFileName(%+v) = %q, se esperaba %q", test.document, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package scrapper
+
+import (
+	"dieg0407/seace/internal/record"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tebeka/selenium"
+)
+
+const (
+	selectOptionSelector = "li.ui-selectonemenu-item"
+	filterWaitTime       = 1 * time.Second
+)
+
+// formField es un campo del formulario de búsqueda. Se busca primero por su id
+// dentro del formulario del modo y, si no existe, por el texto completo de su
+// etiqueta, que es lo que muestra el portal.
+type formField struct {
+	name   string
+	id     string
+	labels []string
+}
+
+var (
+	entityFilter        = formField{name: "entidad", id: "txtNombreEntidad", labels: []string{"nombre o sigla de la entidad", "entidad"}}
+	objectTypeFilter    = formField{name: "tipo de objeto", id: "idObjetoContratacion", labels: []string{"objeto de contratacion", "objeto"}}
+	procedureTypeFilter = formField{name: "tipo de procedimiento", id: "idTipoSeleccion", labels: []string{"tipo de seleccion", "tipo de procedimiento"}}
+	departmentFilter    = formField{name: "departamento", id: "idDepartamento", labels: []string{"departamento"}}
+	descriptionFilter   = formField{name: "descripción", id: "descripcionObjeto", labels: []string{"descripcion del objeto", "descripcion"}}
+	nomenclatureFilter  = formField{name: "nomenclatura", id: "txtNomenclatura", labels: []string{"siglas de la nomenclatura", "nomenclatura"}}
+)
+
+var objectTypes = []string{"Bien", "Servicio", "Obra", "Consultoría de Obra"}
+
+// SearchFilters son los criterios de la búsqueda avanzada además de la fecha.
+// Los filtros vacíos no se llenan en el formulario.
+type SearchFilters struct {
+	Entity        string
+	ObjectType    string
+	ProcedureType string
+	Department    string
+	Description   string
+	Nomenclature  string
+}
+
+func (f SearchFilters) Validate() error {
+	if f.ObjectType == "" {
+		return nil
+	}
+
+	for _, objectType := range objectTypes {
+		if record.Normalize(objectType) == record.Normalize(f.ObjectType) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("tipo de objeto inválido, debes usar uno de: %s", strings.Join(objectTypes, ", "))
+}
+
+func fillFilters(driver selenium.WebDriver, tab selenium.WebElement, mode SearchMode, filters SearchFilters) error {
+	if err := fillText(driver, tab, mode, entityFilter, filters.Entity); err != nil {
+		return fmt.Errorf("no se pudo establecer la entidad:\n%w", err)
+	}
+	if err := selectOption(driver, tab, mode, objectTypeFilter, filters.ObjectType); err != nil {
+		return fmt.Errorf("no se pudo seleccionar el tipo de objeto:\n%w", err)
+	}
+	if err := selectOption(driver, tab, mode, procedureTypeFilter, filters.ProcedureType); err != nil {
+		return fmt.Errorf("no se pudo seleccionar el tipo de procedimiento:\n%w", err)
+	}
+	if err := selectOption(driver, tab, mode, departmentFilter, filters.Department); err != nil {
+		return fmt.Errorf("no se pudo seleccionar el departamento:\n%w", err)
+	}
+	if err := fillText(driver, tab, mode, descriptionFilter, filters.Description); err != nil {
+		return fmt.Errorf("no se pudo establecer la descripción:\n%w", err)
+	}
+	if err := fillText(driver, tab, mode, nomenclatureFilter, filters.Nomenclature); err != nil {
+		return fmt.Errorf("no se pudo establecer la nomenclatura:\n%w", err)
+	}
+
+	return nil
+}
+
+// formLabel es una etiqueta del formulario con el id del campo al que apunta.
+type formLabel struct {
+	text   string
+	target string
+}
+
+// locate devuelve el id del campo en el formulario del modo. Si no lo encuentra
+// el error lista las etiquetas del formulario, para poder corregir el campo
+// cuando el portal cambie.
+func (f formField) locate(tab selenium.WebElement, mode SearchMode) (string, error) {
+	if f.id != "" {
+		if fields, err := tab.FindElements(selenium.ByID, mode.id(f.id)); err == nil && len(fields) > 0 {
+			return mode.id(f.id), nil
+		}
+	}
+
+	elements, err := tab.FindElements(selenium.ByTagName, "label")
+	if err != nil {
+		return "", err
+	}
+
+	labels := []formLabel{}
+	for _, element := range elements {
+		target, err := element.GetAttribute("for")
+		if err != nil || target == "" {
+			continue
+		}
+		text, err := element.Text()
+		if err != nil {
+			return "", err
+		}
+		labels = append(labels, formLabel{text: normalizeLabel(text), target: target})
+	}
+
+	target, err := f.match(labels)
+	if err != nil {
+		return "", fmt.Errorf("%w en el formulario %s", err, mode.form)
+	}
+	return target, nil
+}
+
+// match busca el campo por sus etiquetas en orden, comparando la etiqueta
+// completa sin tildes ni mayúsculas. Si una etiqueta apunta a más de un campo
+// devuelve un error en lugar de elegir uno.
+func (f formField) match(labels []formLabel) (string, error) {
+	for _, wanted := range f.labels {
+		targets := []string{}
+		for _, label := range labels {
+			if label.text == wanted && !slices.Contains(targets, label.target) {
+				targets = append(targets, label.target)
+			}
+		}
+
+		switch len(targets) {
+		case 0:
+			continue
+		case 1:
+			return targets[0], nil
+		default:
+			return "", fmt.Errorf("la etiqueta '%s' del campo %s apunta a varios campos: %s", wanted, f.name, strings.Join(targets, ", "))
+		}
+	}
+
+	texts := make([]string, 0, len(labels))
+	for _, label := range labels {
+		texts = append(texts, label.text)
+	}
+	return "", fmt.Errorf("no se encontró el campo %s, las etiquetas son: %s", f.name, strings.Join(texts, ", "))
+}
+
+func normalizeLabel(text string) string {
+	return strings.Trim(record.Normalize(text), " :*")
+}
+
+// fillText escribe el valor en un campo de texto a través del limitador.
+func fillText(driver selenium.WebDriver, tab selenium.WebElement, mode SearchMode, field formField, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	id, err := field.locate(tab, mode)
+	if err != nil {
+		return err
+	}
+	input, err := tab.FindElement(selenium.ByID, id)
+	if err != nil {
+		return err
+	}
+
+	err = perform(driver, pageAction, func() error {
+		if err := input.Clear(); err != nil {
+			return err
+		}
+		return input.SendKeys(value)
+	}, ajaxIdle)
+	if err != nil {
+		return err
+	}
+
+	time.Sleep(filterWaitTime)
+	return nil
+}
+
+// selectOption abre un selectOneMenu de PrimeFaces y elige la opción cuyo
+// texto coincide con el valor, sin importar tildes ni mayúsculas.
+func selectOption(driver selenium.WebDriver, tab selenium.WebElement, mode SearchMode, field formField, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	id, err := field.locate(tab, mode)
+	if err != nil {
+		return err
+	}
+	// La etiqueta de un selectOneMenu apunta a su input interno
+	selector := strings.TrimSuffix(strings.TrimSuffix(id, "_focus"), "_input")
+	menu, err := tab.FindElement(selenium.ByID, selector)
+	if err != nil {
+		return err
+	}
+	if err := click(driver, menu, pageAction, nil); err != nil {
+		return err
+	}
+	time.Sleep(filterWaitTime)
+
+	// El panel de opciones se dibuja fuera del formulario
+	panel, err := driver.FindElement(selenium.ByID, selector+"_panel")
+	if err != nil {
+		return err
+	}
+	options, err := panel.FindElements(selenium.ByCSSSelector, selectOptionSelector)
+	if err != nil {
+		return err
+	}
+
+	available := make([]string, 0, len(options))
+	for _, option := range options {
+		label, err := option.GetAttribute("data-label")
+		if err != nil || label == "" {
+			if label, err = option.Text(); err != nil {
+				return err
+			}
+		}
+
+		if record.Normalize(label) == record.Normalize(value) {
+			if err := click(driver, option, pageAction, ajaxIdle); err != nil {
+				return err
+			}
+			time.Sleep(filterWaitTime)
+			return nil
+		}
+		available = append(available, label)
+	}
+
+	return fmt.Errorf("no existe la opción '%s', las opciones son: %s", value, strings.Join(available, ", "))
+}
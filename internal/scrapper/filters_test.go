@@ -0,0 +1,68 @@
+package scrapper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormFieldMatch(t *testing.T) {
+	labels := []formLabel{
+		{text: normalizeLabel("Nombre o Sigla de la Entidad:"), target: "form:entidad"},
+		{text: normalizeLabel("Objeto de Contratación: *"), target: "form:objeto_input"},
+		{text: normalizeLabel("Descripción del Objeto:"), target: "form:descripcion"},
+		{text: normalizeLabel("Departamento:"), target: "form:departamento_input"},
+		{text: normalizeLabel("Departamento"), target: "form:departamento_input"},
+		{text: normalizeLabel("Nomenclatura:"), target: "form:nomenclatura"},
+		{text: normalizeLabel("Nomenclatura"), target: "form:nomenclaturaAnterior"},
+	}
+
+	tests := []struct {
+		name    string
+		field   formField
+		want    string
+		wantErr string
+	}{
+		{name: "etiqueta completa", field: entityFilter, want: "form:entidad"},
+		{name: "sin tildes ni mayúsculas", field: objectTypeFilter, want: "form:objeto_input"},
+		{name: "la misma etiqueta dos veces al mismo campo", field: departmentFilter, want: "form:departamento_input"},
+		{name: "no toma una etiqueta que solo la contiene", field: formField{name: "objeto", labels: []string{"objeto"}}, wantErr: "no se encontró el campo objeto"},
+		{name: "etiqueta ambigua", field: nomenclatureFilter, wantErr: "apunta a varios campos"},
+		{name: "sin etiqueta", field: procedureTypeFilter, wantErr: "no se encontró el campo tipo de procedimiento"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.field.match(labels)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("match() = %q, %v, se esperaba un error con %q", got, err, test.wantErr)
+				}
+				return
+			}
+			if err != nil || got != test.want {
+				t.Errorf("match() = %q, %v, se esperaba %q", got, err, test.want)
+			}
+		})
+	}
+}
+
+func TestSearchFiltersValidate(t *testing.T) {
+	tests := []struct {
+		filters SearchFilters
+		wantErr bool
+	}{
+		{filters: SearchFilters{}},
+		{filters: SearchFilters{ObjectType: "Bien"}},
+		{filters: SearchFilters{ObjectType: "consultoria de obra"}},
+		{filters: SearchFilters{ObjectType: " SERVICIO "}},
+		{filters: SearchFilters{ObjectType: "Obras"}, wantErr: true},
+		{filters: SearchFilters{ObjectType: "Consultoría"}, wantErr: true},
+		{filters: SearchFilters{Entity: "Municipalidad", Department: "Lima", Description: "laptops"}},
+	}
+
+	for _, test := range tests {
+		if err := test.filters.Validate(); (err != nil) != test.wantErr {
+			t.Errorf("Validate(%+v) = %v, se esperaba error: %t", test.filters, err, test.wantErr)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package scrapper
+
+import (
+	"dieg0407/seace/internal/followup"
+	"dieg0407/seace/internal/run"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/tebeka/selenium"
+)
+
+// FollowUp vuelve a revisar los procesos de la cola que no se revisaron en el
+// último intervalo. Los registros actualizados van a las salidas de siempre;
+// los que llegan a un estado final salen de la cola y los que se revisaron
+// maxAttempts veces sin resolverse se abandonan.
+func FollowUp(queue *followup.Queue, interval time.Duration, maxAttempts int, options Options) (err error) {
+	logger := log.New(os.Stderr, "[seguimiento] ", log.LstdFlags)
+	limiter.configure(options.Politeness)
+	options.seen = seenRecords{}
+	options.Queue = queue
+	options.summary = run.NewSummary("seguimiento")
+	defer func() { finishRun(options.summary, err, options.Reporters, logger) }()
+
+	due := queue.Due(interval, time.Now())
+	logger.Printf("Hay %d procesos en la cola, %d por revisar\n", len(queue.Processes), len(due))
+	if len(due) == 0 {
+		return nil
+	}
+
+	service, driver, err := openBrowser(logger)
+	if err != nil {
+		return err
+	}
+	defer service.Stop()
+
+	failed := 0
+	for i, pending := range due {
+		logger.Printf("Revisando %s (%d de %d, intento %d)\n", pending.Nomenclature, i+1, len(due), pending.Attempts+1)
+
+		err := revisit(driver, pending, options, logger, i+1)
+		if err != nil {
+			logger.Printf("%s %s:\n%v", errRevisarProceso, pending.Nomenclature, err)
+			failed++
+		}
+
+		abandoned, saveErr := queue.Checked(pending.Key, err, maxAttempts)
+		if saveErr != nil {
+			return saveErr
+		}
+		if abandoned {
+			logger.Printf("El proceso %s no se resolvió en %d revisiones, se saca de la cola\n", pending.Nomenclature, maxAttempts)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d de %d procesos no se pudieron revisar", failed, len(due))
+	}
+
+	logger.Printf("Seguimiento finalizado, quedan %d procesos en la cola\n", len(queue.Processes))
+	return nil
+}
+
+// revisit abre la ficha del proceso con su enlace. Si no se conoce el enlace
+// se busca por su nomenclatura.
+func revisit(driver selenium.WebDriver, pending followup.Pending, options Options, logger *log.Logger, position int) error {
+	if pending.Permalink == "" {
+		if err := reloadSearchPage(driver, logger); err != nil {
+			return err
+		}
+		searchOptions := options
+		searchOptions.Filters = SearchFilters{Nomenclature: pending.Nomenclature}
+		return runSearch(driver, Window{}, searchOptions, logger)
+	}
+
+	if err := navigate(driver, pending.Permalink, fichaOpen, waitForDetailsPageToLoad); err != nil {
+		return fmt.Errorf("error al abrir la ficha:\n%s", err)
+	}
+
+	data, err := extractData(driver, position-1)
+	if err != nil {
+		return fmt.Errorf("error al extraer datos:\n%s", err)
+	}
+
+	return processRecord(driver, data, options, logger, position)
+}
@@ -0,0 +1,337 @@
+package scrapper
+
+import (
+	"dieg0407/seace/internal/record"
+	"fmt"
+	"strings"
+
+	"github.com/tebeka/selenium"
+)
+
+const (
+	tabHeaderSelector   = "ul.ui-tabs-nav > li"
+	dataTableSelector   = "[id='%s'] .ui-datatable"
+	tableHeaderSelector = "[id='%s'] thead th"
+)
+
+// SearchMode describe uno de los tabs del buscador público: cómo abrirlo, cómo
+// llenar su formulario y cómo llegar a la ficha desde sus resultados. Todos
+// los modos producen el mismo registro. Los ids que un modo no conoce (tab,
+// formulario y tabla de resultados) se leen de la página al abrir el tab.
+type SearchMode struct {
+	Name        string
+	Description string
+	tabButton   string
+	tabTitles   []string
+	tabID       string
+	form        string
+	results     string
+	fill        func(driver selenium.WebDriver, tab selenium.WebElement, mode SearchMode, window Window, filters SearchFilters) error
+	details     func(driver selenium.WebDriver, mode SearchMode) (selenium.WebElement, error)
+}
+
+const DefaultSearchMode = "procedimientos"
+
+var searchModes = []SearchMode{
+	{
+		Name:        "procedimientos",
+		Description: "Procedimientos de selección",
+		tabButton:   "/html/body/div[3]/div/div[1]/ul/li[2]",
+		tabID:       "tbBuscador:tab1",
+		form:        "tbBuscador:idFormBuscarProceso",
+		results:     "tbBuscador:idFormBuscarProceso:dtProcesos",
+		fill:        fillDates,
+		details:     detailsActionAt(12, 1),
+	},
+	{
+		Name:        "otros-regimenes",
+		Description: "Contrataciones directas y otros regímenes de contratación",
+		tabTitles:   []string{"otros regimenes", "contratacion directa", "contrataciones directas", "regimen especial"},
+		fill:        fillOtherRegimesForm,
+		details:     detailsActionByHeader,
+	},
+}
+
+var (
+	startDateFilter = formField{name: "fecha de inicio", id: startDateField, labels: []string{"fecha de publicacion desde", "fecha de inicio", "desde"}}
+	endDateFilter   = formField{name: "fecha de fin", id: endDateField, labels: []string{"fecha de publicacion hasta", "fecha de fin", "hasta"}}
+)
+
+// FindSearchMode busca el modo por su nombre, sin importar mayúsculas ni
+// espacios alrededor.
+func FindSearchMode(name string) (SearchMode, error) {
+	for _, mode := range searchModes {
+		if mode.Name == record.Normalize(name) {
+			return mode, nil
+		}
+	}
+
+	return SearchMode{}, fmt.Errorf("modo de búsqueda inválido, debes usar uno de: %s", strings.Join(SearchModeNames(), ", "))
+}
+
+func SearchModeNames() []string {
+	names := make([]string, 0, len(searchModes))
+	for _, mode := range searchModes {
+		names = append(names, mode.Name)
+	}
+	return names
+}
+
+func (m SearchMode) id(field string) string {
+	return m.form + ":" + field
+}
+
+func (m SearchMode) tableData() string {
+	return m.results + "_data"
+}
+
+// findTab devuelve la cabecera del tab del modo. Un modo sin XPath conocido
+// busca el tab por su título y toma el id del panel del enlace de la
+// cabecera; si no lo encuentra, el error lista los tabs de la página.
+func (m SearchMode) findTab(driver selenium.WebDriver) (selenium.WebElement, SearchMode, error) {
+	if m.tabButton != "" {
+		button, err := driver.FindElement(selenium.ByXPATH, m.tabButton)
+		return button, m, err
+	}
+
+	headers, err := driver.FindElements(selenium.ByCSSSelector, tabHeaderSelector)
+	if err != nil {
+		return nil, m, err
+	}
+
+	titles := []string{}
+	for _, header := range headers {
+		text, err := header.Text()
+		if err != nil {
+			return nil, m, err
+		}
+		title := record.Normalize(text)
+		titles = append(titles, title)
+		if !containsAny(title, m.tabTitles) {
+			continue
+		}
+
+		link, err := header.FindElement(selenium.ByTagName, "a")
+		if err != nil {
+			return nil, m, fmt.Errorf("el tab '%s' no tiene enlace:\n%w", title, err)
+		}
+		href, err := link.GetAttribute("href")
+		if err != nil {
+			return nil, m, fmt.Errorf("el tab '%s' no tiene href:\n%w", title, err)
+		}
+		_, panel, found := strings.Cut(href, "#")
+		if !found || panel == "" {
+			return nil, m, fmt.Errorf("el enlace del tab '%s' no indica su panel: %s", title, href)
+		}
+
+		m.tabID = panel
+		return header, m, nil
+	}
+
+	return nil, m, fmt.Errorf("no se encontró el tab del modo %s, los tabs son: %s", m.Name, strings.Join(titles, ", "))
+}
+
+// findForm completa el id del formulario con el del formulario del tab, si el
+// modo no lo conoce.
+func (m SearchMode) findForm(tab selenium.WebElement) (SearchMode, error) {
+	if m.form != "" {
+		return m, nil
+	}
+
+	form, err := tab.FindElement(selenium.ByTagName, "form")
+	if err != nil {
+		return m, fmt.Errorf("el tab %s no tiene formulario:\n%w", m.tabID, err)
+	}
+	id, err := form.GetAttribute("id")
+	if err != nil || id == "" {
+		return m, fmt.Errorf("el formulario del tab %s no tiene id", m.tabID)
+	}
+
+	m.form = id
+	return m, nil
+}
+
+// findResults completa el id de la tabla de resultados con el de la tabla de
+// datos del formulario, si el modo no lo conoce.
+func (m SearchMode) findResults(driver selenium.WebDriver) (SearchMode, error) {
+	if m.results != "" {
+		return m, nil
+	}
+
+	table, err := driver.FindElement(selenium.ByCSSSelector, fmt.Sprintf(dataTableSelector, m.form))
+	if err != nil {
+		return m, fmt.Errorf("el formulario %s no tiene tabla de resultados:\n%w", m.form, err)
+	}
+	id, err := table.GetAttribute("id")
+	if err != nil || id == "" {
+		return m, fmt.Errorf("la tabla de resultados del formulario %s no tiene id", m.form)
+	}
+
+	m.results = id
+	return m, nil
+}
+
+// fillOtherRegimesForm llena el formulario de otros regímenes, que no tiene
+// búsqueda avanzada: solo las fechas, la entidad, la descripción y la
+// nomenclatura.
+func fillOtherRegimesForm(driver selenium.WebDriver, tab selenium.WebElement, mode SearchMode, window Window, filters SearchFilters) error {
+	if filters.ObjectType != "" || filters.ProcedureType != "" || filters.Department != "" {
+		return fmt.Errorf("el modo %s no permite filtrar por tipo de objeto, tipo de procedimiento ni departamento", mode.Name)
+	}
+
+	if !window.IsZero() {
+		if err := fillDateRange(driver, tab, mode, window); err != nil {
+			return err
+		}
+	}
+
+	if err := fillText(driver, tab, mode, entityFilter, filters.Entity); err != nil {
+		return fmt.Errorf("no se pudo establecer la entidad:\n%w", err)
+	}
+	if err := fillText(driver, tab, mode, descriptionFilter, filters.Description); err != nil {
+		return fmt.Errorf("no se pudo establecer la descripción:\n%w", err)
+	}
+	if err := fillText(driver, tab, mode, nomenclatureFilter, filters.Nomenclature); err != nil {
+		return fmt.Errorf("no se pudo establecer la nomenclatura:\n%w", err)
+	}
+
+	return clickSearch(driver, tab, mode)
+}
+
+// detailsActionAt devuelve el enlace a la ficha por su posición en la primera
+// fila de resultados.
+func detailsActionAt(column int, action int) func(selenium.WebDriver, SearchMode) (selenium.WebElement, error) {
+	return func(driver selenium.WebDriver, mode SearchMode) (selenium.WebElement, error) {
+		columns, err := firstRowColumns(driver, mode)
+		if err != nil {
+			return nil, err
+		}
+		if len(columns) <= column {
+			return nil, fmt.Errorf("no se encontraron suficientes columnas en la fila, ¡el formato puede haber cambiado!")
+		}
+
+		actions, err := columns[column].FindElements(selenium.ByTagName, "a")
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo obtener las acciones de la fila:\n%s", err)
+		}
+		if len(actions) <= action {
+			return nil, fmt.Errorf("no se encontraron suficientes acciones en la fila, ¡el formato puede haber cambiado!")
+		}
+
+		return actions[action], nil
+	}
+}
+
+// detailsActionByHeader ubica la columna de acciones por su cabecera y, dentro
+// de ella, el enlace a la ficha por su título. Si la columna tiene un solo
+// enlace se usa ese.
+func detailsActionByHeader(driver selenium.WebDriver, mode SearchMode) (selenium.WebElement, error) {
+	headers, err := driver.FindElements(selenium.ByCSSSelector, fmt.Sprintf(tableHeaderSelector, mode.results))
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo obtener las cabeceras de la tabla:\n%s", err)
+	}
+
+	column := -1
+	names := []string{}
+	for i, header := range headers {
+		text, err := header.Text()
+		if err != nil {
+			return nil, err
+		}
+		name := record.Normalize(text)
+		names = append(names, name)
+		if column < 0 && strings.Contains(name, "accion") {
+			column = i
+		}
+	}
+	if column < 0 {
+		return nil, fmt.Errorf("la tabla de resultados no tiene columna de acciones, las columnas son: %s", strings.Join(names, ", "))
+	}
+
+	columns, err := firstRowColumns(driver, mode)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) <= column {
+		return nil, fmt.Errorf("la fila no tiene la columna de acciones, ¡el formato puede haber cambiado!")
+	}
+	actions, err := columns[column].FindElements(selenium.ByTagName, "a")
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo obtener las acciones de la fila:\n%s", err)
+	}
+
+	titles := []string{}
+	for _, action := range actions {
+		title, err := actionTitle(action)
+		if err != nil {
+			return nil, err
+		}
+		titles = append(titles, title)
+		if strings.Contains(title, "ficha") {
+			return action, nil
+		}
+	}
+	if len(actions) == 1 {
+		return actions[0], nil
+	}
+
+	return nil, fmt.Errorf("no se encontró el enlace a la ficha entre las acciones: %s", strings.Join(titles, ", "))
+}
+
+// actionTitle junta el texto del enlace y los títulos del enlace y de sus
+// íconos, que es donde el portal dice qué hace cada acción.
+func actionTitle(action selenium.WebElement) (string, error) {
+	parts := []string{}
+	text, err := action.Text()
+	if err != nil {
+		return "", err
+	}
+	parts = append(parts, text)
+	if title, err := action.GetAttribute("title"); err == nil {
+		parts = append(parts, title)
+	}
+
+	images, err := action.FindElements(selenium.ByTagName, "img")
+	if err != nil {
+		return "", err
+	}
+	for _, image := range images {
+		for _, attribute := range []string{"title", "alt"} {
+			if value, err := image.GetAttribute(attribute); err == nil {
+				parts = append(parts, value)
+			}
+		}
+	}
+
+	return record.Normalize(strings.Join(parts, " ")), nil
+}
+
+func firstRowColumns(driver selenium.WebDriver, mode SearchMode) ([]selenium.WebElement, error) {
+	tableData, err := driver.FindElement(selenium.ByID, mode.tableData())
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo obtener los datos de la tabla:\n%s", err)
+	}
+
+	rows, err := tableData.FindElements(selenium.ByTagName, "tr")
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo obtener las filas de los datos de la tabla:\n%s", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no se encontraron filas en los datos de la tabla")
+	}
+
+	columns, err := rows[0].FindElements(selenium.ByTagName, "td")
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo obtener las columnas de la fila:\n%s", err)
+	}
+	return columns, nil
+}
+
+func containsAny(value string, candidates []string) bool {
+	for _, candidate := range candidates {
+		if strings.Contains(value, candidate) {
+			return true
+		}
+	}
+	return false
+}
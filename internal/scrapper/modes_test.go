@@ -0,0 +1,34 @@
+package scrapper
+
+import "testing"
+
+func TestFindSearchMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "procedimientos", want: "procedimientos"},
+		{name: "otros-regimenes", want: "otros-regimenes"},
+		{name: " Otros-Regimenes ", want: "otros-regimenes"},
+		{name: "", wantErr: true},
+		{name: "otros regimenes", wantErr: true},
+		{name: "subastas", wantErr: true},
+	}
+
+	for _, test := range tests {
+		mode, err := FindSearchMode(test.name)
+		if (err != nil) != test.wantErr || mode.Name != test.want {
+			t.Errorf("FindSearchMode(%q) = %q, %v, se esperaba %q con error: %t", test.name, mode.Name, err, test.want, test.wantErr)
+		}
+		if err == nil && (mode.fill == nil || mode.details == nil) {
+			t.Errorf("FindSearchMode(%q) devolvió un modo sin formulario o sin enlace a la ficha", test.name)
+		}
+	}
+}
+
+func TestDefaultSearchMode(t *testing.T) {
+	if _, err := FindSearchMode(DefaultSearchMode); err != nil {
+		t.Errorf("el modo por defecto %s no existe: %v", DefaultSearchMode, err)
+	}
+}
@@ -0,0 +1,56 @@
+package scrapper
+
+import (
+	neturl "net/url"
+	"strings"
+
+	"github.com/tebeka/selenium"
+)
+
+// processIDParameters son los parámetros de la URL de la ficha en los que el
+// portal pone el id interno del proceso.
+var processIDParameters = []string{"id", "idProceso", "nid"}
+
+// sessionParameters cambian en cada sesión y no deben quedar en el enlace.
+var sessionParameters = []string{"cid", "windowId", "jftfdi", "jffi"}
+
+// extractPermalink lee la URL de la ficha abierta y devuelve un enlace sin
+// datos de la sesión junto con el id interno del proceso. Si la URL no tiene
+// el id, la ficha no se puede abrir directamente y ambos quedan vacíos.
+func extractPermalink(driver selenium.WebDriver) (string, string) {
+	current, err := driver.CurrentURL()
+	if err != nil {
+		return "", ""
+	}
+	return parsePermalink(current)
+}
+
+func parsePermalink(current string) (string, string) {
+	parsed, err := neturl.Parse(current)
+	if err != nil {
+		return "", ""
+	}
+
+	query := parsed.Query()
+	processID := ""
+	for _, parameter := range processIDParameters {
+		if value := strings.TrimSpace(query.Get(parameter)); value != "" {
+			processID = value
+			break
+		}
+	}
+	if processID == "" {
+		return "", ""
+	}
+
+	for _, parameter := range sessionParameters {
+		query.Del(parameter)
+	}
+	if index := strings.Index(parsed.Path, ";jsessionid="); index >= 0 {
+		parsed.Path = parsed.Path[:index]
+	}
+	parsed.RawQuery = query.Encode()
+	parsed.Fragment = ""
+
+	return parsed.String(), processID
+}
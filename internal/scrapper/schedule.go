@@ -0,0 +1,116 @@
+package scrapper
+
+import (
+	"dieg0407/seace/internal/record"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/tebeka/selenium"
+)
+
+const scheduleDataElement = "tbFicha:dtCronograma_data"
+
+// Los formatos sin ceros aceptan también los días, meses y horas con un solo
+// dígito.
+var portalTimeLayouts = []string{
+	"2/1/2006 15:04:05",
+	"2/1/2006 15:04",
+	"2/1/2006 3:04:05 PM",
+	"2/1/2006 3:04 PM",
+	"2/1/2006",
+}
+
+var limaLocation = mustLoadLocation("America/Lima")
+
+func mustLoadLocation(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		panic(fmt.Sprintf("no se pudo cargar la zona horaria %s: %s", name, err))
+	}
+	return location
+}
+
+// extractSchedule lee el cronograma de la ficha. Si la ficha no lo tiene el
+// cronograma queda vacío, y las fechas que no se pueden interpretar quedan
+// solo como texto.
+func extractSchedule(driver selenium.WebDriver, logger *log.Logger) ([]record.Stage, error) {
+	tables, err := driver.FindElements(selenium.ByID, scheduleDataElement)
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		logger.Println("La ficha no tiene cronograma")
+		return []record.Stage{}, nil
+	}
+
+	rows, err := tables[0].FindElements(selenium.ByTagName, "tr")
+	if err != nil {
+		return nil, err
+	}
+
+	stages := []record.Stage{}
+	for _, row := range rows {
+		columns, err := row.FindElements(selenium.ByTagName, "td")
+		if err != nil {
+			return nil, err
+		}
+		if len(columns) < 3 {
+			continue
+		}
+
+		texts := make([]string, 3)
+		for i := range texts {
+			text, err := columns[i].Text()
+			if err != nil {
+				return nil, err
+			}
+			texts[i] = strings.TrimSpace(text)
+		}
+
+		stage := record.Stage{Name: texts[0], StartRaw: texts[1], EndRaw: texts[2]}
+		if stage.Start, err = parsePortalTime(texts[1]); err != nil {
+			logger.Printf("No se pudo analizar el inicio de la etapa %s: %s\n", stage.Name, err)
+		}
+		if stage.End, err = parsePortalTime(texts[2]); err != nil {
+			logger.Printf("No se pudo analizar el fin de la etapa %s: %s\n", stage.Name, err)
+		}
+		stages = append(stages, stage)
+	}
+
+	return stages, nil
+}
+
+// parsePortalTime interpreta las fechas del portal en la hora de Lima. Un
+// texto vacío no es un error, la etapa simplemente no tiene esa fecha.
+func parsePortalTime(value string) (*time.Time, error) {
+	value = strings.Join(strings.Fields(value), " ")
+	if value == "" || value == "-" {
+		return nil, nil
+	}
+
+	for _, layout := range portalTimeLayouts {
+		parsed, err := time.ParseInLocation(layout, value, limaLocation)
+		if err == nil {
+			return &parsed, nil
+		}
+	}
+
+	return nil, fmt.Errorf("formato de fecha no reconocido: %s", value)
+}
+
+// findAwardDate devuelve la fecha de la etapa de otorgamiento de la buena pro.
+func findAwardDate(schedule []record.Stage) *time.Time {
+	for _, stage := range schedule {
+		if !strings.Contains(record.Normalize(stage.Name), "buena pro") {
+			continue
+		}
+		if stage.Start != nil {
+			return stage.Start
+		}
+		return stage.End
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package scrapper
+
+import (
+	"dieg0407/seace/internal/record"
+	"testing"
+	"time"
+)
+
+func TestParsePortalTime(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "05/03/2024 14:30:15", want: "2024-03-05T14:30:15-05:00"},
+		{value: "05/03/2024 14:30", want: "2024-03-05T14:30:00-05:00"},
+		{value: "5/3/2024 9:05", want: "2024-03-05T09:05:00-05:00"},
+		{value: "05/03/2024 02:30:00 PM", want: "2024-03-05T14:30:00-05:00"},
+		{value: "05/03/2024 9:30 AM", want: "2024-03-05T09:30:00-05:00"},
+		{value: "05/03/2024", want: "2024-03-05T00:00:00-05:00"},
+		{value: "  05/03/2024 \n 14:30 ", want: "2024-03-05T14:30:00-05:00"},
+		{value: "31/12/2024 23:59", want: "2024-12-31T23:59:00-05:00"},
+		{value: ""},
+		{value: "-"},
+		{value: "2024-03-05", wantErr: true},
+		{value: "32/01/2024", wantErr: true},
+		{value: "por definir", wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := parsePortalTime(test.value)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parsePortalTime(%q) error = %v, se esperaba error: %t", test.value, err, test.wantErr)
+			continue
+		}
+		if test.want == "" {
+			if got != nil {
+				t.Errorf("parsePortalTime(%q) = %s, se esperaba nil", test.value, got)
+			}
+			continue
+		}
+		if got == nil || got.Format(time.RFC3339) != test.want || got.Location() != limaLocation {
+			t.Errorf("parsePortalTime(%q) = %v, se esperaba %s en America/Lima", test.value, got, test.want)
+		}
+	}
+}
+
+func TestFindAwardDate(t *testing.T) {
+	start, _ := parsePortalTime("10/03/2024 09:00")
+	end, _ := parsePortalTime("10/03/2024 18:00")
+	call, _ := parsePortalTime("01/03/2024")
+
+	tests := []struct {
+		name     string
+		schedule []record.Stage
+		want     *time.Time
+	}{
+		{name: "sin cronograma"},
+		{name: "sin etapa de buena pro", schedule: []record.Stage{{Name: "Convocatoria", Start: call}}},
+		{
+			name: "inicio de la etapa",
+			schedule: []record.Stage{
+				{Name: "Convocatoria", Start: call},
+				{Name: "Otorgamiento de la Buena Pro", Start: start, End: end},
+			},
+			want: start,
+		},
+		{name: "sin tildes ni mayúsculas", schedule: []record.Stage{{Name: "OTORGAMIENTO DE LA BUENA PRO", Start: start}}, want: start},
+		{name: "solo el fin", schedule: []record.Stage{{Name: "Otorgamiento de la Buena Pro", End: end}}, want: end},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := findAwardDate(test.schedule); got != test.want {
+				t.Errorf("findAwardDate() = %v, se esperaba %v", got, test.want)
+			}
+		})
+	}
+}
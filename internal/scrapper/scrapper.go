@@ -1,6 +1,12 @@
 package scrapper
 
 import (
+	"dieg0407/seace/internal/alerts"
+	"dieg0407/seace/internal/followup"
+	"dieg0407/seace/internal/record"
+	"dieg0407/seace/internal/reference"
+	"dieg0407/seace/internal/run"
+	"dieg0407/seace/internal/store"
 	"fmt"
 	"log"
 	"os"
@@ -16,16 +22,15 @@ import (
 const (
 	// URLs y selectores
 	url                                = "https://prod2.seace.gob.pe/seacebus-uiwd-pub/buscadorPublico/buscadorPublico.xhtml"
-	startDateSelector                  = "tbBuscador:idFormBuscarProceso:dfechaInicio_input"
-	endDateSelector                    = "tbBuscador:idFormBuscarProceso:dfechaFin_input"
-	searchButtonSelector               = "tbBuscador:idFormBuscarProceso:btnBuscarSelToken"
+	startDateField                     = "dfechaInicio_input"
+	endDateField                       = "dfechaFin_input"
+	searchButtonField                  = "btnBuscarSelToken"
+	searchButtonXPath                  = ".//form[@id='%s']//button[contains(normalize-space(.), 'Buscar')]"
 	retrievedRowsDataContainerSelector = ".ui-paginator-current"
 	advancedSearchSelector             = ".ui-fieldset-legend"
-	tableDataSelector                  = "tbBuscador:idFormBuscarProceso:dtProcesos_data"
 	nextPageButton                     = ".ui-paginator-next"
 	previousPageButton                 = ".ui-paginator-prev"
-	selectionProceduresTabButton       = "/html/body/div[3]/div/div[1]/ul/li[2]"
-	selectionProceduresTabID           = "tbBuscador:tab1"
+	rowSelectorFormat                  = "[id='%s'] tr[data-ri='%d']"
 
 	// Constantes de tiempo
 	initialWaitTime     = 2 * time.Second
@@ -35,90 +40,261 @@ const (
 	pageNavigationDelay = 5 * time.Second
 
 	// Mensajes de error
-	errIniciarServicioSelenium = "Error al iniciar el servicio de Selenium"
-	errAbrirNavegador          = "Error al abrir el navegador"
-	errEncontrarTab            = "Error al encontrar el tab de procedimientos de selección"
-	errHacerClicTab            = "Error al hacer clic en el tab de procedimientos de selección"
-	errObtenerTab              = "Error al obtener el tab de procedimientos de selección"
-	errRellenarFechas          = "Error al rellenar las fechas"
-	errDesplazarsePagina       = "Error al desplazarse al final de la página"
-	errEncontrarFilas          = "Error al encontrar la cantidad total de filas"
-	errEsperarCargaPagina      = "Error al esperar a que la página se cargue"
-	errExtraerIdentificador    = "Error al extraer el identificador de fila"
-	errSeleccionarElemento     = "Error al seleccionar el elemento"
-	errNoRegistros             = "No se obtuvieron registros"
-	errProcesarRegistro        = "Error al procesar el registro"
-	screenshotsDir             = "screenshots"
+	errIniciarServicioSelenium  = "Error al iniciar el servicio de Selenium"
+	errAbrirNavegador           = "Error al abrir el navegador"
+	errEncontrarTab             = "Error al encontrar el tab de procedimientos de selección"
+	errHacerClicTab             = "Error al hacer clic en el tab de procedimientos de selección"
+	errObtenerTab               = "Error al obtener el tab de procedimientos de selección"
+	errRellenarFechas           = "Error al rellenar las fechas y filtros"
+	errDesplazarsePagina        = "Error al desplazarse al final de la página"
+	errEncontrarFilas           = "Error al encontrar la cantidad total de filas"
+	errEsperarCargaPagina       = "Error al esperar a que la página se cargue"
+	errExtraerIdentificador     = "Error al extraer el identificador de fila"
+	errSeleccionarElemento      = "Error al seleccionar el elemento"
+	errNoRegistros              = "No se obtuvieron registros"
+	errProcesarRegistro         = "Error al procesar el registro"
+	errEscribirRegistro         = "Error al escribir el registro"
+	errDescargarDocumentos      = "Error al descargar los documentos del registro"
+	errGuardarRegistro          = "Error al guardar en el almacén el registro"
+	errEncolarRegistro          = "Error al actualizar la cola de seguimiento con el registro"
+	errRevisarProceso           = "Error al revisar el proceso"
+	errNotificarAlerta          = "Error al notificar la alerta del proceso"
+	errReportarEjecucion        = "Error al reportar el resumen de la ejecución"
+	errNomenclaturaNoEncontrada = "No se encontró ningún proceso con la nomenclatura"
+	errBuscarNomenclatura       = "Error al buscar la nomenclatura"
+	screenshotsDir              = "screenshots"
 )
 
-func Start(date time.Time) {
+type Options struct {
+	Politeness         Politeness
+	Writers            []record.Writer
+	DownloadDocuments  bool
+	DocumentsDirectory string
+	Entities           *reference.Entities
+	Rates              *reference.Rates
+	Store              *store.Store
+	Queue              *followup.Queue
+	Watchlist          *alerts.Watchlist
+	Notifiers          []alerts.Notifier
+	Rules              *alerts.Rules
+	Filters            SearchFilters
+	Mode               SearchMode
+	ResultCap          int
+	Reporters          []run.Reporter
+	// WriteUnchanged escribe también los procesos que no cambiaron según el
+	// almacén, para las salidas que deben quedar completas por sí solas.
+	WriteUnchanged bool
+
+	seen    seenRecords
+	summary *run.Summary
+}
+
+func Start(date time.Time, options Options) error {
+	return StartRange(SingleDay(date), options)
+}
+
+// StartRange procesa todos los procesos de la ventana. Si el portal reporta
+// un total en el tope configurado, la ventana se parte hasta que cada parte
+// quede por debajo del tope.
+func StartRange(window Window, options Options) (err error) {
 	logger := log.New(os.Stderr, "[scrapper] ", log.LstdFlags)
-	logger.Printf("Proceso inicializado para la fecha: %s\n", date.Format("2006-01-02"))
+	logger.Printf("Proceso inicializado para la fecha: %s\n", window)
+	limiter.configure(options.Politeness)
+	options.seen = seenRecords{}
+	options.summary = run.NewSummary("fecha " + window.String())
+	defer func() { finishRun(options.summary, err, options.Reporters, logger) }()
+
+	service, driver, err := openBrowser(logger)
+	if err != nil {
+		return err
+	}
+	defer service.Stop()
+
+	if err := runSearch(driver, window, options, logger); err != nil {
+		return err
+	}
+
+	logger.Println("Proceso finalizado exitosamente")
+	return nil
+}
+
+// Search busca cada nomenclatura por separado y extrae solo las fichas que
+// coinciden con ella. Como es una actualización pedida a propósito, las fichas
+// se escriben aunque no hayan cambiado desde la última vez, y una nomenclatura
+// sin resultados cuenta como fallida.
+func Search(nomenclatures []string, options Options) (err error) {
+	logger := log.New(os.Stderr, "[scrapper] ", log.LstdFlags)
+	logger.Printf("Búsqueda inicializada para %d nomenclaturas\n", len(nomenclatures))
+	limiter.configure(options.Politeness)
+	options.seen = seenRecords{}
+	options.WriteUnchanged = true
+	options.summary = run.NewSummary(fmt.Sprintf("búsqueda de %d nomenclaturas", len(nomenclatures)))
+	defer func() { finishRun(options.summary, err, options.Reporters, logger) }()
+
+	service, driver, err := openBrowser(logger)
+	if err != nil {
+		return err
+	}
+	defer service.Stop()
+
+	failed := 0
+	for i, nomenclature := range nomenclatures {
+		logger.Printf("Buscando la nomenclatura %s (%d de %d)\n", nomenclature, i+1, len(nomenclatures))
+
+		if i > 0 {
+			if err := reloadSearchPage(driver, logger); err != nil {
+				return err
+			}
+		}
+
+		searchOptions := options
+		searchOptions.Filters.Nomenclature = nomenclature
+		processed := options.summary.Processed
+		if err := runSearch(driver, Window{}, searchOptions, logger); err != nil {
+			logger.Printf("%s %s:\n%v", errBuscarNomenclatura, nomenclature, err)
+			failed++
+		} else if options.summary.Processed == processed {
+			logger.Printf("%s %s\n", errNomenclaturaNoEncontrada, nomenclature)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d de %d nomenclaturas no se pudieron procesar", failed, len(nomenclatures))
+	}
 
+	logger.Println("Búsqueda finalizada exitosamente")
+	return nil
+}
+
+func openBrowser(logger *log.Logger) (*selenium.Service, selenium.WebDriver, error) {
 	service, err := selenium.NewChromeDriverService("chromedriver", 4444)
 	if err != nil {
 		logger.Printf("%s:\n%v", errIniciarServicioSelenium, err)
-		return
+		return nil, nil, err
 	}
-	defer service.Stop()
 
 	driver, err := setupDriver()
 	if err != nil {
 		logger.Printf("%s:\n%v", errAbrirNavegador, err)
-		return
+		service.Stop()
+		return nil, nil, err
 	}
 
-	if err := inicializarTabProcedimientos(driver, logger); err != nil {
-		return
+	return service, driver, nil
+}
+
+func reloadSearchPage(driver selenium.WebDriver, logger *log.Logger) error {
+	if err := navigate(driver, url, pageAction, documentReady); err != nil {
+		logger.Printf("%s:\n%v", errAbrirNavegador, err)
+		return err
+	}
+	return nil
+}
+
+func runSearch(driver selenium.WebDriver, window Window, options Options, logger *log.Logger) error {
+	if options.Mode.fill == nil {
+		options.Mode, _ = FindSearchMode(DefaultSearchMode)
 	}
+	logger.Printf("Modo de búsqueda: %s\n", options.Mode.Description)
 
-	tab, err := getSelectionProcessTab(driver)
+	mode, err := inicializarTabProcedimientos(driver, options.Mode, logger)
+	if err != nil {
+		return err
+	}
+
+	tab, err := getSelectionProcessTab(driver, mode)
 	if err != nil {
 		logger.Printf("%s:\n%v", errObtenerTab, err)
-		return
+		return err
+	}
+	if options.Mode, err = mode.findForm(tab); err != nil {
+		logger.Printf("%s:\n%v", errObtenerTab, err)
+		return err
 	}
 
-	if err := realizarBusqueda(driver, tab, date, logger); err != nil {
-		return
+	if err := realizarBusqueda(driver, tab, window, options, logger); err != nil {
+		return err
 	}
 
-	tab, err = getSelectionProcessTab(driver)
+	tab, err = getSelectionProcessTab(driver, options.Mode)
 	if err != nil {
 		logger.Printf("%s:\n%v", errObtenerTab, err)
-		return
+		return err
 	}
 
 	recordsObtained, err := findTotalAmountOfRows(tab)
 	if err != nil {
 		logger.Printf("%s:\n%v", errEncontrarFilas, err)
-		return
+		return err
 	}
 
-	if err := procesarRegistros(driver, recordsObtained, logger); err != nil {
-		return
+	if isCapped(recordsObtained, options.ResultCap) {
+		logger.Printf("La búsqueda %s obtuvo %d filas, en el tope de %d\n", window, recordsObtained, options.ResultCap)
+		if done, err := runSplitSearch(driver, window, options, logger); done {
+			return err
+		}
+		logger.Printf("La búsqueda %s no se puede partir más, los resultados pueden estar incompletos\n", window)
 	}
 
-	logger.Println("Proceso finalizado exitosamente")
+	return procesarRegistros(driver, recordsObtained, options, logger)
 }
 
-func inicializarTabProcedimientos(driver selenium.WebDriver, logger *log.Logger) error {
-	button, err := driver.FindElement(selenium.ByXPATH, selectionProceduresTabButton)
+// runSplitSearch repite la búsqueda en ventanas más pequeñas o, si la ventana
+// es de un solo día, por cada tipo de objeto. Devuelve false si no se pudo
+// partir la búsqueda.
+func runSplitSearch(driver selenium.WebDriver, window Window, options Options, logger *log.Logger) (bool, error) {
+	if windows, ok := window.split(); ok {
+		for _, part := range windows {
+			logger.Printf("Partiendo la búsqueda, procesando %s\n", part)
+			if err := reloadSearchPage(driver, logger); err != nil {
+				return true, err
+			}
+			if err := runSearch(driver, part, options, logger); err != nil {
+				return true, err
+			}
+		}
+		return true, nil
+	}
+
+	if filters, ok := splitFilters(options.Filters, options.Mode); ok {
+		for _, part := range filters {
+			logger.Printf("Partiendo la búsqueda %s, procesando el tipo de objeto %s\n", window, part.ObjectType)
+			if err := reloadSearchPage(driver, logger); err != nil {
+				return true, err
+			}
+			partOptions := options
+			partOptions.Filters = part
+			if err := runSearch(driver, window, partOptions, logger); err != nil {
+				return true, err
+			}
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// inicializarTabProcedimientos abre el tab del modo y devuelve el modo con el
+// id del tab que se encontró en la página.
+func inicializarTabProcedimientos(driver selenium.WebDriver, mode SearchMode, logger *log.Logger) (SearchMode, error) {
+	button, mode, err := mode.findTab(driver)
 	if err != nil {
 		logger.Printf("%s:\n%v", errEncontrarTab, err)
-		return err
+		return mode, err
 	}
 
-	if err := button.Click(); err != nil {
+	if err := click(driver, button, pageAction, ajaxIdle); err != nil {
 		logger.Printf("%s:\n%v", errHacerClicTab, err)
-		return err
+		return mode, err
 	}
 
 	time.Sleep(initialWaitTime)
-	return nil
+	return mode, nil
 }
 
-func realizarBusqueda(driver selenium.WebDriver, tab selenium.WebElement, date time.Time, logger *log.Logger) error {
-	if err := fillDates(tab, date); err != nil {
+func realizarBusqueda(driver selenium.WebDriver, tab selenium.WebElement, window Window, options Options, logger *log.Logger) error {
+	if err := options.Mode.fill(driver, tab, options.Mode, window, options.Filters); err != nil {
 		logger.Printf("%s:\n%v", errRellenarFechas, err)
 		return err
 	}
@@ -154,7 +330,7 @@ func takeScreenshot(driver selenium.WebDriver, filename string) error {
 	return nil
 }
 
-func procesarRegistros(driver selenium.WebDriver, recordsObtained int64, logger *log.Logger) error {
+func procesarRegistros(driver selenium.WebDriver, recordsObtained int64, options Options, logger *log.Logger) error {
 	if recordsObtained == 0 {
 		logger.Println(errNoRegistros)
 		return nil
@@ -162,29 +338,48 @@ func procesarRegistros(driver selenium.WebDriver, recordsObtained int64, logger
 
 	logger.Printf("Cantidad total de filas obtenidas: %d\n", recordsObtained)
 
-	if err := waitForTableToLoad(driver); err != nil {
+	mode, err := options.Mode.findResults(driver)
+	if err != nil {
+		logger.Printf("%s:\n%v", errEsperarCargaPagina, err)
+		return err
+	}
+	options.Mode = mode
+
+	if err := waitForTableToLoad(driver, options.Mode); err != nil {
 		logger.Printf("%s:\n%v", errEsperarCargaPagina, err)
 		return err
 	}
 
-	rowIdentifierFormat, err := extractRowIdentifierFormat(driver)
+	rowIdentifierFormat, err := extractRowIdentifierFormat(driver, options.Mode)
 	if err != nil {
 		logger.Printf("%s:\n%v", errExtraerIdentificador, err)
 		return err
 	}
 	logger.Printf("Formato de identificador de fila extraído: %s\n", rowIdentifierFormat)
 
-	printHeader()
 	for i := 0; i < int(recordsObtained); i++ {
 		logger.Printf("Procesando registro %d de %d\n", i+1, recordsObtained)
 
-		tab, err := getSelectionProcessTab(driver)
+		tab, err := getSelectionProcessTab(driver, options.Mode)
 		if err != nil {
 			logger.Printf("%s:\n%v", errObtenerTab, err)
 			return err
 		}
 
-		if err := selectElement(driver, tab, i, rowIdentifierFormat, logger); err != nil {
+		if options.Filters.Nomenclature != "" {
+			matches, err := rowMatchesNomenclature(driver, tab, options.Mode, i, options.Filters.Nomenclature, logger)
+			if err != nil {
+				logger.Printf("%s %d:\n%v", errProcesarRegistro, i+1, err)
+				return err
+			}
+			if !matches {
+				logger.Printf("El registro %d no corresponde a la nomenclatura %s\n", i+1, options.Filters.Nomenclature)
+				continue
+			}
+		}
+
+		data, err := selectElement(driver, tab, i, rowIdentifierFormat, logger)
+		if err != nil {
 			logger.Printf("%s %d:\n%v", errProcesarRegistro, i+1, err)
 
 			// Tomar screenshot del error
@@ -195,27 +390,159 @@ func procesarRegistros(driver selenium.WebDriver, recordsObtained int64, logger
 				logger.Printf("Screenshot guardado como: %s", screenshotName)
 			}
 
-			break
+			return fmt.Errorf("%s %d:\n%w", errProcesarRegistro, i+1, err)
+		}
+
+		if err := processRecord(driver, data, options, logger, i+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// processRecord completa el registro extraído de una ficha y lo envía a las
+// salidas, salvo que ya se haya procesado en esta ejecución o no haya
+// cambiado desde una anterior.
+func processRecord(driver selenium.WebDriver, data record.Record, options Options, logger *log.Logger, position int) error {
+	options.summary.Processed++
+	if options.seen != nil && !options.seen.add(data) {
+		logger.Printf("El registro %d (%s) ya fue procesado en otra búsqueda, se omite\n", position, data.Nomenclature)
+		options.summary.Duplicated++
+		return nil
+	}
+
+	// El hash se toma antes de completar el registro con las tablas locales,
+	// así editar esas tablas no hace que todos los procesos parezcan cambiados
+	data.Hash = data.ContentHash()
+
+	options.Entities.Enrich(&data)
+	options.Rates.Convert(&data)
+	if data.ConversionError != "" {
+		logger.Printf("No se pudo convertir a soles el valor del registro %d: %s\n", position, data.ConversionError)
+	}
+
+	options.Rules.Tag(&data)
+	if len(data.Tags) > 0 {
+		logger.Printf("El registro %d (%s) coincide con las etiquetas: %s\n", position, data.Nomenclature, strings.Join(data.Tags, ", "))
+		options.summary.Tagged++
+	}
+
+	pending, err := options.Queue.Track(data)
+	if err != nil {
+		logger.Printf("%s %d:\n%v", errEncolarRegistro, position, err)
+		return err
+	}
+	if pending {
+		logger.Printf("El registro %d (%s) no tiene ganador, queda en la cola de seguimiento\n", position, data.Nomenclature)
+		options.summary.Pending++
+	}
+
+	if !options.WriteUnchanged && options.Store.Unchanged(data) {
+		logger.Printf("El registro %d (%s) no cambió desde una ejecución anterior, se omite\n", position, data.Nomenclature)
+		options.summary.Unchanged++
+		if err := options.Store.Put(data); err != nil {
+			logger.Printf("%s %d:\n%v", errGuardarRegistro, position, err)
+			return err
+		}
+		return nil
+	}
+
+	if options.DownloadDocuments {
+		if err := downloadDocuments(driver, &data, options.DocumentsDirectory, logger); err != nil {
+			logger.Printf("%s %d:\n%v", errDescargarDocumentos, position, err)
+		}
+	}
+
+	if err := writeRecord(options.Writers, data); err != nil {
+		logger.Printf("%s %d:\n%v", errEscribirRegistro, position, err)
+		return err
+	}
+	options.summary.Record(data)
+	if err := options.Store.Put(data); err != nil {
+		logger.Printf("%s %d:\n%v", errGuardarRegistro, position, err)
+		return err
+	}
+
+	if alert, ok := options.Watchlist.Match(data); ok {
+		notify(options.Notifiers, alert, logger)
+		options.summary.Alerts++
+	}
+
+	logger.Printf("Registro %d procesado correctamente\n", position)
+	return nil
+}
+
+func rowMatchesNomenclature(driver selenium.WebDriver, tab selenium.WebElement, mode SearchMode, id int, nomenclature string, logger *log.Logger) (bool, error) {
+	if err := goToPage(driver, tab, calculatePageNumber(id), logger); err != nil {
+		return false, fmt.Errorf("error al ir a la página %d:\n%s", calculatePageNumber(id), err)
+	}
+
+	row, err := driver.FindElement(selenium.ByCSSSelector, fmt.Sprintf(rowSelectorFormat, mode.tableData(), id))
+	if err != nil {
+		return false, fmt.Errorf("error al obtener la fila %d:\n%s", id, err)
+	}
+	columns, err := row.FindElements(selenium.ByTagName, "td")
+	if err != nil {
+		return false, fmt.Errorf("error al obtener las columnas de la fila %d:\n%s", id, err)
+	}
+
+	texts := make([]string, 0, len(columns))
+	for _, column := range columns {
+		text, err := column.Text()
+		if err != nil {
+			return false, err
 		}
+		texts = append(texts, text)
+	}
 
-		logger.Printf("Registro %d procesado correctamente\n", i+1)
+	return matchesNomenclature(texts, nomenclature), nil
+}
+
+// matchesNomenclature indica si alguna columna de la fila es exactamente la
+// nomenclatura buscada, sin importar tildes, mayúsculas ni espacios. El
+// buscador también devuelve procesos cuya nomenclatura solo contiene la
+// buscada, y esos no se extraen.
+func matchesNomenclature(columns []string, nomenclature string) bool {
+	for _, column := range columns {
+		if record.Normalize(column) == record.Normalize(nomenclature) {
+			return true
+		}
 	}
+	return false
+}
+
+// notify envía la alerta a todos los notificadores. Un notificador que falla
+// no detiene la ejecución.
+func notify(notifiers []alerts.Notifier, alert alerts.Alert, logger *log.Logger) {
+	for _, notifier := range notifiers {
+		if err := notifier.Notify(alert); err != nil {
+			logger.Printf("%s %s:\n%v", errNotificarAlerta, alert.Nomenclature, err)
+		}
+	}
+}
 
+func writeRecord(writers []record.Writer, data record.Record) error {
+	for _, writer := range writers {
+		if err := writer.Write(data); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func waitForTableToLoad(driver selenium.WebDriver) error {
+func waitForTableToLoad(driver selenium.WebDriver, mode SearchMode) error {
 	return driver.WaitWithTimeout(func(wd selenium.WebDriver) (bool, error) {
-		_, err := wd.FindElement(selenium.ByID, tableDataSelector)
+		_, err := wd.FindElement(selenium.ByID, mode.tableData())
 		return err == nil, nil
 	}, pageLoadTimeout)
 }
 
-func getSelectionProcessTab(driver selenium.WebDriver) (selenium.WebElement, error) {
+func getSelectionProcessTab(driver selenium.WebDriver, mode SearchMode) (selenium.WebElement, error) {
 	// Esperar a que el tab esté presente y sea interactivo
 	err := driver.WaitWithTimeout(func(wd selenium.WebDriver) (bool, error) {
 		// Intentar encontrar el tab
-		tab, err := wd.FindElement(selenium.ByID, selectionProceduresTabID)
+		tab, err := wd.FindElement(selenium.ByID, mode.tabID)
 		if err != nil {
 			return false, nil // No es un error, solo que aún no está disponible
 		}
@@ -239,7 +566,7 @@ func getSelectionProcessTab(driver selenium.WebDriver) (selenium.WebElement, err
 	}
 
 	// Una vez que sabemos que el elemento está disponible, lo obtenemos
-	tab, err := driver.FindElement(selenium.ByID, selectionProceduresTabID)
+	tab, err := driver.FindElement(selenium.ByID, mode.tabID)
 	if err != nil {
 		return nil, fmt.Errorf("no se pudo encontrar el formulario de procedimientos de selección:\n%w", err)
 	}
@@ -257,8 +584,9 @@ func setupDriver() (selenium.WebDriver, error) {
 		return nil, err
 	}
 
-	err = driver.Get(url)
+	err = navigate(driver, url, pageAction, documentReady)
 	if err != nil {
+		driver.Quit()
 		return nil, err
 	}
 
@@ -270,45 +598,76 @@ func setupDriver() (selenium.WebDriver, error) {
 	return driver, nil
 }
 
-func fillDates(tab selenium.WebElement, date time.Time) error {
+func fillDates(driver selenium.WebDriver, tab selenium.WebElement, mode SearchMode, window Window, filters SearchFilters) error {
 	advancedSearchButton, err := tab.FindElement(selenium.ByCSSSelector, advancedSearchSelector)
 	if err != nil {
 		return fmt.Errorf("no se pudo obtener el botón de búsqueda avanzada:\n%w", err)
 	}
 
-	err = advancedSearchButton.Click()
+	err = click(driver, advancedSearchButton, pageAction, nil)
 	if err != nil {
 		return fmt.Errorf("no se pudo hacer clic en el botón de búsqueda avanzada:\n%w", err)
 	}
 
 	time.Sleep(2 * time.Second)
-	formattedDate := date.Format("02/01/2006")
-	startDateSelector, err := tab.FindElement(selenium.ByID, startDateSelector)
+	if !window.IsZero() {
+		if err := fillDateRange(driver, tab, mode, window); err != nil {
+			return err
+		}
+	}
+
+	if err := fillFilters(driver, tab, mode, filters); err != nil {
+		return err
+	}
+
+	return clickSearch(driver, tab, mode)
+}
+
+func clickSearch(driver selenium.WebDriver, tab selenium.WebElement, mode SearchMode) error {
+	button, err := tab.FindElement(selenium.ByID, mode.id(searchButtonField))
+	if err != nil {
+		// Los formularios sin id conocido se buscan por el texto del botón
+		button, err = tab.FindElement(selenium.ByXPATH, fmt.Sprintf(searchButtonXPath, mode.form))
+	}
+	if err != nil {
+		return fmt.Errorf("no se pudo obtener el botón de búsqueda:\n%w", err)
+	}
+	err = click(driver, button, pageAction, ajaxIdle)
+	if err != nil {
+		return fmt.Errorf("no se pudo hacer clic en el botón de búsqueda:\n%w", err)
+	}
+
+	return nil
+}
+
+func fillDateRange(driver selenium.WebDriver, tab selenium.WebElement, mode SearchMode, window Window) error {
+	formattedStartDate := window.From.Format("02/01/2006")
+	formattedEndDate := window.To.Format("02/01/2006")
+	startDateID, err := startDateFilter.locate(tab, mode)
+	if err != nil {
+		return fmt.Errorf("no se pudo obtener el selector de fecha de inicio:\n%w", err)
+	}
+	startDateSelector, err := tab.FindElement(selenium.ByID, startDateID)
 	if err != nil {
 		return fmt.Errorf("no se pudo obtener el selector de fecha de inicio:\n%w", err)
 	}
-	err = startDateSelector.SendKeys(formattedDate)
+	err = perform(driver, pageAction, func() error { return startDateSelector.SendKeys(formattedStartDate) }, nil)
 	if err != nil {
 		return fmt.Errorf("no se pudo establecer el valor de la fecha de inicio:\n%w", err)
 	}
 
 	time.Sleep(2 * time.Second)
-	endDateSelector, err := tab.FindElement(selenium.ByID, endDateSelector)
+	endDateID, err := endDateFilter.locate(tab, mode)
 	if err != nil {
 		return fmt.Errorf("no se pudo obtener el selector de fecha de fin:\n%w", err)
 	}
-	err = endDateSelector.SendKeys(formattedDate)
-	if err != nil {
-		return fmt.Errorf("no se pudo establecer el valor de la fecha de fin:\n%w", err)
-	}
-
-	button, err := tab.FindElement(selenium.ByID, searchButtonSelector)
+	endDateSelector, err := tab.FindElement(selenium.ByID, endDateID)
 	if err != nil {
-		return fmt.Errorf("no se pudo obtener el botón de búsqueda:\n%w", err)
+		return fmt.Errorf("no se pudo obtener el selector de fecha de fin:\n%w", err)
 	}
-	err = button.Click()
+	err = perform(driver, pageAction, func() error { return endDateSelector.SendKeys(formattedEndDate) }, nil)
 	if err != nil {
-		return fmt.Errorf("no se pudo hacer clic en el botón de búsqueda:\n%w", err)
+		return fmt.Errorf("no se pudo establecer el valor de la fecha de fin:\n%w", err)
 	}
 
 	return nil
@@ -339,39 +698,12 @@ func findTotalAmountOfRows(tab selenium.WebElement) (int64, error) {
 	return total, nil
 }
 
-func extractRowIdentifierFormat(driver selenium.WebDriver) (string, error) {
-	tableData, err := driver.FindElement(selenium.ByID, tableDataSelector)
-	if err != nil {
-		return "", fmt.Errorf("no se pudo obtener los datos de la tabla:\n%s", err)
-	}
-
-	rows, err := tableData.FindElements(selenium.ByTagName, "tr")
-	if err != nil {
-		return "", fmt.Errorf("no se pudo obtener las filas de los datos de la tabla:\n%s", err)
-	}
-
-	if len(rows) == 0 {
-		return "", fmt.Errorf("no se encontraron filas en los datos de la tabla")
-	}
-
-	columns, err := rows[0].FindElements(selenium.ByTagName, "td")
-	if err != nil {
-		return "", fmt.Errorf("no se pudo obtener las columnas de la fila:\n%s", err)
-	}
-
-	if len(columns) < 13 {
-		return "", fmt.Errorf("no se encontraron suficientes columnas en la fila, ¡el formato puede haber cambiado!")
-	}
-
-	actions, err := columns[12].FindElements(selenium.ByTagName, "a")
+func extractRowIdentifierFormat(driver selenium.WebDriver, mode SearchMode) (string, error) {
+	goToElementAction, err := mode.details(driver, mode)
 	if err != nil {
-		return "", fmt.Errorf("no se pudo obtener las acciones de la fila:\n%s", err)
-	}
-	if len(actions) < 2 {
-		return "", fmt.Errorf("no se encontraron suficientes acciones en la fila, ¡el formato puede haber cambiado!")
+		return "", err
 	}
 
-	goToElementAction := actions[1]
 	attribute, err := goToElementAction.GetAttribute("id")
 	if err != nil {
 		return "", fmt.Errorf("no se pudo obtener el atributo id de la acción:\n%s", err)
@@ -380,50 +712,40 @@ func extractRowIdentifierFormat(driver selenium.WebDriver) (string, error) {
 	return strings.Replace(attribute, ":0:", ":%d:", 1), nil
 }
 
-func selectElement(driver selenium.WebDriver, tab selenium.WebElement, id int, rowIdentifierFormat string, logger *log.Logger) error {
+func selectElement(driver selenium.WebDriver, tab selenium.WebElement, id int, rowIdentifierFormat string, logger *log.Logger) (record.Record, error) {
 	err := goToPage(driver, tab, calculatePageNumber(id), logger)
 	if err != nil {
-		return fmt.Errorf("error al ir a la página %d:\n%s", calculatePageNumber(id), err)
+		return record.Record{}, fmt.Errorf("error al ir a la página %d:\n%s", calculatePageNumber(id), err)
 	}
 
 	formattedId := fmt.Sprintf(rowIdentifierFormat, id)
 	element, err := driver.FindElement(selenium.ByID, formattedId)
 	if err != nil {
-		return fmt.Errorf("error al obtener el elemento con id %d e id sin formato '%s':\n%s", id, formattedId, err)
-	}
-
-	err = element.Click()
-	if err != nil {
-		return fmt.Errorf("error al hacer clic en el elemento con id %d e id sin formato '%s':\n%s", id, formattedId, err)
+		return record.Record{}, fmt.Errorf("error al obtener el elemento con id %d e id sin formato '%s':\n%s", id, formattedId, err)
 	}
 
-	err = driver.WaitWithTimeout(waitForDetailsPageToLoad, 30*time.Second)
+	err = click(driver, element, fichaOpen, waitForDetailsPageToLoad)
 	if err != nil {
-		return fmt.Errorf("error al esperar a que se cargue la página de detalles:\n%s", err)
+		return record.Record{}, fmt.Errorf("error al abrir la ficha del elemento con id %d e id sin formato '%s':\n%s", id, formattedId, err)
 	}
 
 	// Extraer información
-	err = extractData(driver, id)
+	data, err := extractData(driver, id)
 	if err != nil {
-		return fmt.Errorf("error al extraer datos:\n%s", err)
+		return record.Record{}, fmt.Errorf("error al extraer datos:\n%s", err)
 	}
 
 	// Regresar
 	element, err = driver.FindElement(selenium.ByXPATH, "//button[span[text()='Regresar']]")
 	if err != nil {
-		return fmt.Errorf("error al obtener el botón Regresar:\n%s", err)
-	}
-	err = element.Click()
-	if err != nil {
-		return fmt.Errorf("error al hacer clic en el botón Regresar:\n%s", err)
+		return record.Record{}, fmt.Errorf("error al obtener el botón Regresar:\n%s", err)
 	}
-
-	err = driver.WaitWithTimeout(waitForMainPageToLoad, 30*time.Second)
+	err = click(driver, element, pageAction, waitForMainPageToLoad)
 	if err != nil {
-		return fmt.Errorf("error al esperar a que se cargue la página principal:\n%s", err)
+		return record.Record{}, fmt.Errorf("error al regresar a la página principal:\n%s", err)
 	}
 
-	return nil
+	return data, nil
 }
 
 func waitForDetailsPageToLoad(wd selenium.WebDriver) (bool, error) {
@@ -500,7 +822,7 @@ func goToPage(driver selenium.WebDriver, tab selenium.WebElement, page int, logg
 	if activePage < page {
 		// Avanzar
 		logger.Println("avanzando")
-		err = clickNextPage(tab)
+		err = clickNextPage(driver, tab)
 		if err != nil {
 			return fmt.Errorf("error al hacer clic en el botón de siguiente página:\n%s", err)
 		}
@@ -512,14 +834,14 @@ func goToPage(driver selenium.WebDriver, tab selenium.WebElement, page int, logg
 	}
 
 	logger.Println("retrocediendo")
-	err = clickPreviousPage(tab)
+	err = clickPreviousPage(driver, tab)
 	if err != nil {
 		return fmt.Errorf("error al hacer clic en el botón de página anterior:\n%s", err)
 	}
 	return goToPage(driver, tab, page, logger)
 }
 
-func clickNextPage(tab selenium.WebElement) error {
+func clickNextPage(driver selenium.WebDriver, tab selenium.WebElement) error {
 	nextPage, err := tab.FindElement(selenium.ByCSSSelector, nextPageButton)
 	if err != nil {
 		return fmt.Errorf("error al obtener el botón de siguiente página:\n%s", err)
@@ -534,7 +856,7 @@ func clickNextPage(tab selenium.WebElement) error {
 		return fmt.Errorf("el botón de siguiente página está deshabilitado")
 	}
 
-	err = nextPage.Click()
+	err = click(driver, nextPage, pageAction, ajaxIdle)
 	if err != nil {
 		return fmt.Errorf("error al hacer clic en el botón de siguiente página:\n%s", err)
 	}
@@ -542,7 +864,7 @@ func clickNextPage(tab selenium.WebElement) error {
 	return nil
 }
 
-func clickPreviousPage(tab selenium.WebElement) error {
+func clickPreviousPage(driver selenium.WebDriver, tab selenium.WebElement) error {
 	previousPage, err := tab.FindElement(selenium.ByCSSSelector, previousPageButton)
 	if err != nil {
 		return fmt.Errorf("error al obtener el botón de página anterior:\n%s", err)
@@ -557,7 +879,7 @@ func clickPreviousPage(tab selenium.WebElement) error {
 		return fmt.Errorf("el botón de página anterior está deshabilitado")
 	}
 
-	err = previousPage.Click()
+	err = click(driver, previousPage, pageAction, ajaxIdle)
 	if err != nil {
 		return fmt.Errorf("error al hacer clic en el botón de página anterior:\n%s", err)
 	}
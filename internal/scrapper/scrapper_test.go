@@ -0,0 +1,28 @@
+package scrapper
+
+import "testing"
+
+func TestMatchesNomenclature(t *testing.T) {
+	row := []string{"1", "MUNICIPALIDAD DE LIMA", "AS-SM-12-2024-MPL-1", "Bien"}
+
+	tests := []struct {
+		name         string
+		columns      []string
+		nomenclature string
+		want         bool
+	}{
+		{name: "exacta", columns: row, nomenclature: "AS-SM-12-2024-MPL-1", want: true},
+		{name: "sin importar mayúsculas ni espacios", columns: row, nomenclature: "  as-sm-12-2024-mpl-1 ", want: true},
+		{name: "solo contiene la buscada", columns: []string{"AS-SM-12-2024-MPL-1-2"}, nomenclature: "AS-SM-12-2024-MPL-1"},
+		{name: "otra nomenclatura", columns: row, nomenclature: "AS-SM-13-2024-MPL-1"},
+		{name: "fila vacía", nomenclature: "AS-SM-12-2024-MPL-1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := matchesNomenclature(test.columns, test.nomenclature); got != test.want {
+				t.Errorf("matchesNomenclature(%q, %q) = %t, se esperaba %t", test.columns, test.nomenclature, got, test.want)
+			}
+		})
+	}
+}
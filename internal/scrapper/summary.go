@@ -0,0 +1,17 @@
+package scrapper
+
+import (
+	"dieg0407/seace/internal/run"
+	"log"
+)
+
+// finishRun cierra el resumen con el resultado de la ejecución y lo entrega a
+// los reporters. Un reporter que falla no cambia el resultado.
+func finishRun(summary *run.Summary, err error, reporters []run.Reporter, logger *log.Logger) {
+	summary.Finish(err)
+	for _, reporter := range reporters {
+		if reportErr := reporter.RunFinished(*summary); reportErr != nil {
+			logger.Printf("%s:\n%v", errReportarEjecucion, reportErr)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package scrapper
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tebeka/selenium"
+)
+
+// Politeness define los límites de cortesía frente al portal del SEACE.
+type Politeness struct {
+	ActionsPerMinute  int
+	FichasPerMinute   int
+	QuietHours        QuietHours
+	SlowLoadThreshold time.Duration
+	MaxSlowdown       float64
+}
+
+// QuietHours es una ventana horaria [Start, End), en la hora de Lima, en la
+// que no se realizan acciones sobre el portal. Si Start es mayor que End la
+// ventana cruza la medianoche.
+type QuietHours struct {
+	Start   int
+	End     int
+	Enabled bool
+}
+
+type actionKind int
+
+const (
+	pageAction actionKind = iota
+	fichaOpen
+)
+
+const (
+	defaultActionsPerMinute  = 20
+	defaultFichasPerMinute   = 6
+	defaultSlowLoadThreshold = 10 * time.Second
+	defaultMaxSlowdown       = 8
+	slowdownIncrease         = 2
+	slowdownRecovery         = 0.9
+)
+
+// Títulos de las páginas de error del servidor o del balanceador. La palabra
+// "error" solo cuenta cuando es todo el título, porque aparece en títulos que
+// no son de error.
+var errorPageMarkers = []string{
+	"service unavailable",
+	"too many requests",
+	"bad gateway",
+	"gateway timeout",
+	"internal server error",
+	"request rejected",
+}
+
+// errorStatusTitle reconoce los títulos de las páginas de estado HTTP, como
+// "503 Service Unavailable" o "HTTP Status 500 - Internal Server Error".
+var errorStatusTitle = regexp.MustCompile(`^(http status |http error |error )?(429|50[0-4])\b`)
+
+func DefaultPoliteness() Politeness {
+	return Politeness{
+		ActionsPerMinute:  defaultActionsPerMinute,
+		FichasPerMinute:   defaultFichasPerMinute,
+		SlowLoadThreshold: defaultSlowLoadThreshold,
+		MaxSlowdown:       defaultMaxSlowdown,
+	}
+}
+
+func ParseQuietHours(value string) (QuietHours, error) {
+	if value == "" {
+		return QuietHours{}, nil
+	}
+
+	parts := strings.Split(value, "-")
+	if len(parts) != 2 {
+		return QuietHours{}, fmt.Errorf("formato de horas de silencio inválido, debes usar HH-HH: %s", value)
+	}
+
+	start, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || start < 0 || start > 23 {
+		return QuietHours{}, fmt.Errorf("hora de inicio de silencio inválida: %s", parts[0])
+	}
+	end, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil || end < 0 || end > 23 {
+		return QuietHours{}, fmt.Errorf("hora de fin de silencio inválida: %s", parts[1])
+	}
+
+	return QuietHours{Start: start, End: end, Enabled: start != end}, nil
+}
+
+func (q QuietHours) contains(t time.Time) bool {
+	if !q.Enabled {
+		return false
+	}
+	hour := t.In(limaLocation).Hour()
+	if q.Start < q.End {
+		return hour >= q.Start && hour < q.End
+	}
+	return hour >= q.Start || hour < q.End
+}
+
+func (q QuietHours) end(t time.Time) time.Time {
+	t = t.In(limaLocation)
+	end := time.Date(t.Year(), t.Month(), t.Day(), q.End, 0, 0, 0, limaLocation)
+	if !end.After(t) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+// throttler lleva el ritmo de las acciones sobre el portal. Todas las
+// acciones, incluida la apertura de fichas, comparten el tope de acciones por
+// minuto; las fichas además respetan su propio tope. Solo se usa a través de
+// perform.
+type throttler struct {
+	mu        sync.Mutex
+	config    Politeness
+	last      time.Time
+	lastFicha time.Time
+	slowdown  float64
+	logger    *log.Logger
+}
+
+var limiter = newThrottler(DefaultPoliteness())
+
+func newThrottler(config Politeness) *throttler {
+	return &throttler{
+		config:   config,
+		slowdown: 1,
+		logger:   log.New(os.Stderr, "[limitador] ", log.LstdFlags),
+	}
+}
+
+func (t *throttler) configure(config Politeness) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if config.MaxSlowdown < 1 {
+		config.MaxSlowdown = 1
+	}
+	t.config = config
+	t.slowdown = 1
+}
+
+// wait reserva el turno de la acción y duerme hasta que llegue, sin retener
+// el candado para que las demás acciones y observe no queden bloqueadas
+// durante las horas de silencio.
+func (t *throttler) wait(kind actionKind) {
+	if delay := time.Until(t.reserve(kind, time.Now())); delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// reserve calcula el primer momento desde now en el que la acción puede
+// ejecutarse y lo registra como la última acción.
+func (t *throttler) reserve(kind actionKind, now time.Time) time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	at := now
+	if !t.last.IsZero() {
+		at = latest(at, t.last.Add(t.interval(pageAction)))
+	}
+	if kind == fichaOpen && !t.lastFicha.IsZero() {
+		at = latest(at, t.lastFicha.Add(t.interval(fichaOpen)))
+	}
+	if t.config.QuietHours.contains(at) {
+		at = t.config.QuietHours.end(at)
+		t.logger.Printf("Horario de silencio activo, esperando hasta %s\n", at.Format("15:04"))
+	}
+
+	t.last = at
+	if kind == fichaOpen {
+		t.lastFicha = at
+	}
+	return at
+}
+
+func (t *throttler) interval(kind actionKind) time.Duration {
+	perMinute := t.config.ActionsPerMinute
+	if kind == fichaOpen {
+		perMinute = t.config.FichasPerMinute
+	}
+	if perMinute <= 0 {
+		return 0
+	}
+
+	return time.Duration(float64(time.Minute) / float64(perMinute) * t.slowdown)
+}
+
+func latest(a time.Time, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+func (t *throttler) observe(load time.Duration, errorPage bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	previous := t.slowdown
+	switch {
+	case errorPage || (t.config.SlowLoadThreshold > 0 && load > t.config.SlowLoadThreshold):
+		t.slowdown = min(t.slowdown*slowdownIncrease, t.config.MaxSlowdown)
+	default:
+		t.slowdown = max(t.slowdown*slowdownRecovery, 1)
+	}
+
+	if t.slowdown > previous {
+		t.logger.Printf("Reduciendo la velocidad (x%.1f), carga de %s, página de error: %t\n", t.slowdown, load.Round(time.Millisecond), errorPage)
+	}
+}
+
+// perform es el único punto por el que pasan las acciones sobre el portal:
+// espera el turno que le da el limitador, ejecuta la acción y, si se indica
+// una condición de carga, espera a que se cumpla y ajusta la velocidad según
+// lo que tardó.
+func perform(driver selenium.WebDriver, kind actionKind, action func() error, loaded selenium.Condition) error {
+	limiter.wait(kind)
+	start := time.Now()
+	if err := action(); err != nil {
+		return err
+	}
+	if loaded == nil {
+		return nil
+	}
+
+	err := driver.WaitWithTimeout(loaded, elementWaitTimeout)
+	limiter.observe(time.Since(start), isErrorPage(driver))
+	if err != nil {
+		return fmt.Errorf("la página no terminó de cargar:\n%w", err)
+	}
+	return nil
+}
+
+func click(driver selenium.WebDriver, element selenium.WebElement, kind actionKind, loaded selenium.Condition) error {
+	return perform(driver, kind, element.Click, loaded)
+}
+
+func navigate(driver selenium.WebDriver, address string, kind actionKind, loaded selenium.Condition) error {
+	return perform(driver, kind, func() error { return driver.Get(address) }, loaded)
+}
+
+// ajaxIdle se cumple cuando PrimeFaces no tiene peticiones pendientes.
+func ajaxIdle(driver selenium.WebDriver) (bool, error) {
+	idle, err := driver.ExecuteScript("return !window.PrimeFaces || PrimeFaces.ajax.Queue.isEmpty();", nil)
+	if err != nil {
+		return false, nil
+	}
+	return idle == true, nil
+}
+
+// documentReady se cumple cuando el navegador terminó de cargar la página.
+func documentReady(driver selenium.WebDriver) (bool, error) {
+	state, err := driver.ExecuteScript("return document.readyState;", nil)
+	if err != nil {
+		return false, nil
+	}
+	return state == "complete", nil
+}
+
+func isErrorPage(driver selenium.WebDriver) bool {
+	title, err := driver.Title()
+	if err != nil {
+		return false
+	}
+
+	return isErrorTitle(title)
+}
+
+func isErrorTitle(title string) bool {
+	title = strings.ToLower(strings.TrimSpace(title))
+	if title == "error" || errorStatusTitle.MatchString(title) {
+		return true
+	}
+	for _, marker := range errorPageMarkers {
+		if strings.Contains(title, marker) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,192 @@
+package scrapper
+
+import (
+	"io"
+	"log"
+	"testing"
+	"time"
+)
+
+func TestParseQuietHours(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    QuietHours
+		wantErr bool
+	}{
+		{value: "", want: QuietHours{}},
+		{value: "08-18", want: QuietHours{Start: 8, End: 18, Enabled: true}},
+		{value: " 22 - 6 ", want: QuietHours{Start: 22, End: 6, Enabled: true}},
+		{value: "5-5", want: QuietHours{Start: 5, End: 5}},
+		{value: "08", wantErr: true},
+		{value: "08-18-20", wantErr: true},
+		{value: "ocho-18", wantErr: true},
+		{value: "08-24", wantErr: true},
+		{value: "-1-5", wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseQuietHours(test.value)
+		if (err != nil) != test.wantErr || got != test.want {
+			t.Errorf("ParseQuietHours(%q) = %+v, %v, se esperaba %+v con error: %t", test.value, got, err, test.want, test.wantErr)
+		}
+	}
+}
+
+func TestQuietHours(t *testing.T) {
+	at := func(hour int, minute int) time.Time {
+		return time.Date(2024, 11, 1, hour, minute, 0, 0, limaLocation)
+	}
+
+	tests := []struct {
+		name     string
+		quiet    QuietHours
+		now      time.Time
+		contains bool
+		end      time.Time
+	}{
+		{name: "desactivado", quiet: QuietHours{Start: 8, End: 18}, now: at(10, 0)},
+		{name: "dentro del día", quiet: QuietHours{Start: 8, End: 18, Enabled: true}, now: at(8, 0), contains: true, end: at(18, 0)},
+		{name: "fin del día excluido", quiet: QuietHours{Start: 8, End: 18, Enabled: true}, now: at(18, 0)},
+		{name: "antes del día", quiet: QuietHours{Start: 8, End: 18, Enabled: true}, now: at(7, 59)},
+		{name: "cruza la medianoche de noche", quiet: QuietHours{Start: 22, End: 6, Enabled: true}, now: at(23, 30), contains: true, end: at(6, 0).AddDate(0, 0, 1)},
+		{name: "cruza la medianoche de madrugada", quiet: QuietHours{Start: 22, End: 6, Enabled: true}, now: at(2, 15), contains: true, end: at(6, 0)},
+		{name: "cruza la medianoche fuera", quiet: QuietHours{Start: 22, End: 6, Enabled: true}, now: at(12, 0)},
+		{name: "cruza la medianoche al terminar", quiet: QuietHours{Start: 22, End: 6, Enabled: true}, now: at(6, 0)},
+		{name: "usa la hora de Lima", quiet: QuietHours{Start: 8, End: 18, Enabled: true}, now: time.Date(2024, 11, 1, 14, 0, 0, 0, time.UTC), contains: true, end: at(18, 0)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.quiet.contains(test.now); got != test.contains {
+				t.Fatalf("contains(%s) = %t, se esperaba %t", test.now.Format("15:04"), got, test.contains)
+			}
+			if test.contains && !test.quiet.end(test.now).Equal(test.end) {
+				t.Errorf("end(%s) = %s, se esperaba %s", test.now.Format("15:04"), test.quiet.end(test.now), test.end)
+			}
+		})
+	}
+}
+
+func TestThrottlerSlowdown(t *testing.T) {
+	const slow = 20 * time.Second
+	const fast = time.Second
+
+	tests := []struct {
+		name     string
+		loads    []time.Duration
+		errors   []bool
+		slowdown float64
+	}{
+		{name: "sin observaciones", slowdown: 1},
+		{name: "carga lenta", loads: []time.Duration{slow}, slowdown: 2},
+		{name: "página de error", loads: []time.Duration{fast}, errors: []bool{true}, slowdown: 2},
+		{name: "tope", loads: []time.Duration{slow, slow, slow, slow, slow}, slowdown: 8},
+		{name: "recupera de a poco", loads: []time.Duration{slow, fast}, slowdown: 1.8},
+		{name: "no baja de uno", loads: []time.Duration{slow, fast, fast, fast, fast, fast, fast, fast, fast}, slowdown: 1},
+		{name: "en el umbral no es lenta", loads: []time.Duration{10 * time.Second}, slowdown: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			throttler := newThrottler(DefaultPoliteness())
+			throttler.logger = log.New(io.Discard, "", 0)
+			for i, load := range test.loads {
+				throttler.observe(load, i < len(test.errors) && test.errors[i])
+			}
+
+			if diff := throttler.slowdown - test.slowdown; diff > 1e-9 || diff < -1e-9 {
+				t.Fatalf("slowdown = %v, se esperaba %v", throttler.slowdown, test.slowdown)
+			}
+			if got, want := throttler.interval(pageAction), time.Duration(float64(3*time.Second)*test.slowdown); got != want {
+				t.Errorf("interval(pageAction) = %s, se esperaba %s", got, want)
+			}
+			if got, want := throttler.interval(fichaOpen), time.Duration(float64(10*time.Second)*test.slowdown); got != want {
+				t.Errorf("interval(fichaOpen) = %s, se esperaba %s", got, want)
+			}
+		})
+	}
+}
+
+func TestThrottlerConfigure(t *testing.T) {
+	throttler := newThrottler(DefaultPoliteness())
+	throttler.slowdown = 4
+	throttler.configure(Politeness{ActionsPerMinute: 0, FichasPerMinute: 30, MaxSlowdown: 0})
+
+	if throttler.slowdown != 1 || throttler.config.MaxSlowdown != 1 {
+		t.Errorf("configure() dejó slowdown = %v y MaxSlowdown = %v, se esperaba 1 y 1", throttler.slowdown, throttler.config.MaxSlowdown)
+	}
+	if got := throttler.interval(pageAction); got != 0 {
+		t.Errorf("interval(pageAction) sin límite = %s, se esperaba 0", got)
+	}
+	if got := throttler.interval(fichaOpen); got != 2*time.Second {
+		t.Errorf("interval(fichaOpen) = %s, se esperaba 2s", got)
+	}
+}
+
+func TestThrottlerReserve(t *testing.T) {
+	start := time.Date(2024, 11, 1, 12, 0, 0, 0, limaLocation)
+
+	tests := []struct {
+		name    string
+		quiet   QuietHours
+		actions []actionKind
+		want    []time.Duration
+	}{
+		{
+			name:    "acciones de página",
+			actions: []actionKind{pageAction, pageAction, pageAction},
+			want:    []time.Duration{0, 3 * time.Second, 6 * time.Second},
+		},
+		{
+			name:    "las fichas cuentan para el tope de acciones",
+			actions: []actionKind{fichaOpen, pageAction, pageAction},
+			want:    []time.Duration{0, 3 * time.Second, 6 * time.Second},
+		},
+		{
+			name:    "las fichas respetan además su propio tope",
+			actions: []actionKind{fichaOpen, pageAction, fichaOpen, pageAction},
+			want:    []time.Duration{0, 3 * time.Second, 10 * time.Second, 13 * time.Second},
+		},
+		{
+			name:    "horas de silencio",
+			quiet:   QuietHours{Start: 12, End: 13, Enabled: true},
+			actions: []actionKind{pageAction, pageAction},
+			want:    []time.Duration{time.Hour, time.Hour + 3*time.Second},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := DefaultPoliteness()
+			config.QuietHours = test.quiet
+			throttler := newThrottler(config)
+			throttler.logger = log.New(io.Discard, "", 0)
+
+			for i, kind := range test.actions {
+				if got := throttler.reserve(kind, start); !got.Equal(start.Add(test.want[i])) {
+					t.Errorf("reserve() de la acción %d = %s, se esperaba %s", i+1, got.Sub(start), test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIsErrorTitle(t *testing.T) {
+	tests := map[string]bool{
+		"503 Service Unavailable":                    true,
+		"HTTP Status 500 - Internal Server Error":    true,
+		"429 Too Many Requests":                      true,
+		"502 Bad Gateway":                            true,
+		"The request was rejected: Request Rejected": true,
+		"Buscador Público":                           false,
+		"Registro de errores de la convocatoria":     false,
+		"Error":                                      true,
+		"Proceso 503-2024":                           false,
+	}
+
+	for title, want := range tests {
+		if got := isErrorTitle(title); got != want {
+			t.Errorf("isErrorTitle(%q) = %t, se esperaba %t", title, got, want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package scrapper
+
+import (
+	"dieg0407/seace/internal/record"
+	"fmt"
+	"time"
+)
+
+// Window es el rango de fechas de una búsqueda. Una ventana vacía no llena las
+// fechas del formulario.
+type Window struct {
+	From time.Time
+	To   time.Time
+}
+
+func SingleDay(date time.Time) Window {
+	return Window{From: date, To: date}
+}
+
+func (w Window) IsZero() bool {
+	return w.From.IsZero() && w.To.IsZero()
+}
+
+func (w Window) days() int {
+	return int(w.To.Sub(w.From).Hours()/24) + 1
+}
+
+func (w Window) String() string {
+	if w.IsZero() {
+		return "sin fechas"
+	}
+	if w.days() == 1 {
+		return w.From.Format("2006-01-02")
+	}
+	return fmt.Sprintf("%s a %s", w.From.Format("2006-01-02"), w.To.Format("2006-01-02"))
+}
+
+// split parte la ventana en dos mitades. Devuelve false si la ventana es de un
+// solo día y no se puede partir.
+func (w Window) split() ([]Window, bool) {
+	if w.IsZero() || w.days() <= 1 {
+		return nil, false
+	}
+
+	middle := w.From.AddDate(0, 0, w.days()/2-1)
+	return []Window{
+		{From: w.From, To: middle},
+		{From: middle.AddDate(0, 0, 1), To: w.To},
+	}, true
+}
+
+// isCapped indica si el total de filas está en el tope configurado o cerca de
+// él, en cuyo caso el portal pudo haber truncado los resultados.
+func isCapped(total int64, resultCap int) bool {
+	if resultCap <= 0 {
+		return false
+	}
+	margin := int64(resultCap) / 100
+	return total >= int64(resultCap)-margin
+}
+
+// splitFilters reparte una búsqueda por tipo de objeto cuando la ventana ya no
+// se puede partir por fechas.
+func splitFilters(filters SearchFilters, mode SearchMode) ([]SearchFilters, bool) {
+	if filters.ObjectType != "" || mode.Name != DefaultSearchMode {
+		return nil, false
+	}
+
+	result := make([]SearchFilters, 0, len(objectTypes))
+	for _, objectType := range objectTypes {
+		split := filters
+		split.ObjectType = objectType
+		result = append(result, split)
+	}
+	return result, true
+}
+
+// seenRecords evita escribir dos veces el mismo proceso cuando una búsqueda se
+// parte en varias ventanas que se solapan.
+type seenRecords map[string]bool
+
+func (s seenRecords) add(data record.Record) bool {
+	key := data.StableKey()
+	if key == "" {
+		return true
+	}
+	if s[key] {
+		return false
+	}
+	s[key] = true
+	return true
+}
@@ -0,0 +1,92 @@
+package scrapper
+
+import (
+	"testing"
+	"time"
+)
+
+func date(value string) time.Time {
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+func TestWindowSplit(t *testing.T) {
+	tests := []struct {
+		name   string
+		window Window
+		want   []Window
+	}{
+		{
+			name:   "ventana vacía",
+			window: Window{},
+		},
+		{
+			name:   "un solo día",
+			window: SingleDay(date("2024-11-01")),
+		},
+		{
+			name:   "dos días",
+			window: Window{From: date("2024-11-01"), To: date("2024-11-02")},
+			want: []Window{
+				SingleDay(date("2024-11-01")),
+				SingleDay(date("2024-11-02")),
+			},
+		},
+		{
+			name:   "días impares",
+			window: Window{From: date("2024-11-01"), To: date("2024-11-05")},
+			want: []Window{
+				{From: date("2024-11-01"), To: date("2024-11-02")},
+				{From: date("2024-11-03"), To: date("2024-11-05")},
+			},
+		},
+		{
+			name:   "cruza el mes",
+			window: Window{From: date("2024-10-30"), To: date("2024-11-02")},
+			want: []Window{
+				{From: date("2024-10-30"), To: date("2024-10-31")},
+				{From: date("2024-11-01"), To: date("2024-11-02")},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := test.window.split()
+			if ok != (test.want != nil) {
+				t.Fatalf("split() ok = %t, se esperaba %t", ok, test.want != nil)
+			}
+			if len(got) != len(test.want) {
+				t.Fatalf("split() = %v, se esperaba %v", got, test.want)
+			}
+			for i := range got {
+				if !got[i].From.Equal(test.want[i].From) || !got[i].To.Equal(test.want[i].To) {
+					t.Errorf("split()[%d] = %s, se esperaba %s", i, got[i], test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIsCapped(t *testing.T) {
+	tests := []struct {
+		total     int64
+		resultCap int
+		want      bool
+	}{
+		{total: 1000, resultCap: 0, want: false},
+		{total: 999, resultCap: 1000, want: true},
+		{total: 990, resultCap: 1000, want: true},
+		{total: 989, resultCap: 1000, want: false},
+		{total: 1200, resultCap: 1000, want: true},
+	}
+
+	for _, test := range tests {
+		if got := isCapped(test.total, test.resultCap); got != test.want {
+			t.Errorf("isCapped(%d, %d) = %t, se esperaba %t", test.total, test.resultCap, got, test.want)
+		}
+	}
+}
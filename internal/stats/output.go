@@ -0,0 +1,79 @@
+package stats
+
+import (
+	"dieg0407/seace/internal/table"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+var csvHeader = []string{"Dimensión", "Nombre", "Procesos", "Items", "Monto PEN"}
+
+func (s Summary) WriteJSON(out io.Writer) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// WriteCSV escribe una fila por grupo de cada dimensión, separando las
+// columnas por `;` como el resto de reportes. Los totales van en la dimensión
+// total y los ganadores MYPE y de la selva en las dimensiones mype y selva,
+// contados por item adjudicado.
+func (s Summary) WriteCSV(out io.Writer) error {
+	rows := [][]string{{"total", "total", strconv.Itoa(s.Processes), strconv.Itoa(s.Items), s.Amount.String()}}
+
+	for _, dimension := range s.Dimensions() {
+		for _, group := range dimension.Groups {
+			rows = append(rows, []string{dimension.Name, group.Name, strconv.Itoa(group.Processes), strconv.Itoa(group.Items), group.Amount.String()})
+		}
+	}
+
+	for _, share := range []struct {
+		name  string
+		share Share
+	}{{"mype", s.MYPE}, {"selva", s.Selva}} {
+		for _, value := range []struct {
+			name  string
+			count int
+		}{{"si", share.share.Yes}, {"no", share.share.No}, {"desconocido", share.share.Unknown}} {
+			rows = append(rows, []string{share.name, value.name, "", strconv.Itoa(value.count), ""})
+		}
+	}
+
+	return table.NewWriter(out, csvHeader).Write(rows...)
+}
+
+// WriteTable escribe el resumen alineado en columnas para leerlo en la
+// terminal.
+func (s Summary) WriteTable(out io.Writer) error {
+	table := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(table, "Procesos\t%d\n", s.Processes)
+	fmt.Fprintf(table, "Items\t%d\n", s.Items)
+	fmt.Fprintf(table, "Items adjudicados\t%d\n", s.Awarded)
+	fmt.Fprintf(table, "Valor total (PEN)\t%s\n", s.Amount.String())
+	if s.WithoutPEN > 0 {
+		fmt.Fprintf(table, "Procesos sin valor en soles\t%d\n", s.WithoutPEN)
+	}
+	fmt.Fprintf(table, "Ganadores MYPE\t%.1f%%\t(%d sí, %d no, %d sin dato)\n", s.MYPE.Percent(), s.MYPE.Yes, s.MYPE.No, s.MYPE.Unknown)
+	fmt.Fprintf(table, "Ganadores de la selva\t%.1f%%\t(%d sí, %d no, %d sin dato)\n", s.Selva.Percent(), s.Selva.Yes, s.Selva.No, s.Selva.Unknown)
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	for _, dimension := range s.Dimensions() {
+		fmt.Fprintf(out, "\nPor %s\n", dimension.Name)
+		table = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(table, "\tProcesos\tItems\tMonto PEN\n")
+		for _, group := range dimension.Groups {
+			fmt.Fprintf(table, "%s\t%d\t%d\t%s\n", group.Name, group.Processes, group.Items, group.Amount.String())
+		}
+		if err := table.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
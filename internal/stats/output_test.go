@@ -0,0 +1,45 @@
+package stats
+
+import (
+	"bytes"
+	"dieg0407/seace/internal/table"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	summary := Summary{
+		Processes: 1,
+		Items:     1,
+		Amount:    decimal("100"),
+		ByWinner:  []Group{{Name: `CONSORCIO "EL SOL"; NORTE`, Processes: 1, Items: 1, Amount: decimal("100")}},
+	}
+
+	out := &bytes.Buffer{}
+	if err := summary.WriteCSV(out); err != nil {
+		t.Fatalf("WriteCSV() = %v", err)
+	}
+
+	winners := []string{}
+	rows := 0
+	err := table.Read(out, "las estadísticas", len(csvHeader), func(line int, columns []string) error {
+		rows++
+		if len(columns) != len(csvHeader) {
+			t.Errorf("la línea %d tiene %d columnas, se esperaban %d", line, len(columns), len(csvHeader))
+		}
+		if columns[0] == "ganador" {
+			winners = append(winners, columns[1])
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Read() = %v", err)
+	}
+
+	// total, el ganador y tres filas por cada proporción de MYPE y selva
+	if rows != 8 {
+		t.Errorf("se escribieron %d filas, se esperaban 8", rows)
+	}
+	if len(winners) != 1 || winners[0] != `CONSORCIO "EL SOL"; NORTE` {
+		t.Errorf("ganadores = %q, se esperaba el nombre con comillas y punto y coma", winners)
+	}
+}
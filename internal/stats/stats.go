@@ -0,0 +1,245 @@
+package stats
+
+import (
+	"dieg0407/seace/internal/money"
+	"dieg0407/seace/internal/record"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+)
+
+const unknownGroup = "(sin dato)"
+
+// Group son los totales de un valor de una dimensión, por ejemplo de una
+// entidad o de un departamento. Amount está en soles.
+type Group struct {
+	Name      string        `json:"nombre"`
+	Processes int           `json:"procesos"`
+	Items     int           `json:"items"`
+	Amount    money.Decimal `json:"monto_pen"`
+}
+
+// Share es cuántos ganadores son MYPE (o de la selva), cuántos no y de cuántos
+// no se sabe.
+type Share struct {
+	Yes     int `json:"si"`
+	No      int `json:"no"`
+	Unknown int `json:"desconocido"`
+}
+
+// Summary son las estadísticas de un conjunto de procesos.
+type Summary struct {
+	From         *time.Time    `json:"desde,omitempty"`
+	To           *time.Time    `json:"hasta,omitempty"`
+	Processes    int           `json:"procesos"`
+	Items        int           `json:"items"`
+	Awarded      int           `json:"items_adjudicados"`
+	Amount       money.Decimal `json:"valor_pen"`
+	WithoutPEN   int           `json:"procesos_sin_valor_pen"`
+	ByEntity     []Group       `json:"por_entidad"`
+	ByWinner     []Group       `json:"por_ganador"`
+	ByObjectType []Group       `json:"por_objeto"`
+	ByCurrency   []Group       `json:"por_moneda"`
+	ByDepartment []Group       `json:"por_departamento"`
+	MYPE         Share         `json:"mype"`
+	Selva        Share         `json:"selva"`
+}
+
+// Dimension es una de las agrupaciones del resumen.
+type Dimension struct {
+	Name   string
+	Groups []Group
+}
+
+func (s Summary) Dimensions() []Dimension {
+	return []Dimension{
+		{"entidad", s.ByEntity},
+		{"ganador", s.ByWinner},
+		{"objeto", s.ByObjectType},
+		{"moneda", s.ByCurrency},
+		{"departamento", s.ByDepartment},
+	}
+}
+
+// Compute agrupa los procesos cuya fecha (la primera del cronograma) está en
+// el rango [from, to]. Un rango vacío incluye todos los procesos. Los montos
+// de los procesos son su valor en soles; los de los ganadores, el monto
+// adjudicado convertido con el mismo tipo de cambio. Con limit mayor a cero
+// solo se conservan los primeros grupos de cada dimensión.
+func Compute(records []record.Record, from *time.Time, to *time.Time, limit int) Summary {
+	summary := Summary{From: from, To: to, Amount: money.NewDecimal(new(big.Rat), 2)}
+	entities := groups{}
+	winners := groups{}
+	objectTypes := groups{}
+	currencies := groups{}
+	departments := groups{}
+
+	for _, data := range records {
+		if !inRange(data, from, to) {
+			continue
+		}
+
+		amount, ok := amountPEN(data)
+		if !ok {
+			summary.WithoutPEN++
+		}
+		summary.Processes++
+		summary.Items += len(data.Items)
+		summary.Amount = summary.Amount.Add(amount)
+
+		entities.add(data.Entity, len(data.Items), amount)
+		objectTypes.add(data.ObjectType, len(data.Items), amount)
+		currencies.add(firstNonEmpty(data.CurrencyCode, data.Currency), len(data.Items), amount)
+		departments.add(data.Department, len(data.Items), amount)
+
+		processWinners := map[string]bool{}
+		for _, item := range data.Items {
+			winner, hasWinner := item.Winner()
+			if !hasWinner {
+				continue
+			}
+			summary.Awarded++
+			summary.MYPE.add(winner.IsMYPE)
+			summary.Selva.add(winner.IsSelva)
+
+			name := firstNonEmpty(winner.Name, winner.RUC)
+			key := record.Normalize(name)
+			awarded, _ := awardedPEN(data, item)
+			winners.item(name, awarded, !processWinners[key])
+			processWinners[key] = true
+		}
+	}
+
+	summary.ByEntity = entities.sorted(limit)
+	summary.ByWinner = winners.sorted(limit)
+	summary.ByObjectType = objectTypes.sorted(limit)
+	summary.ByCurrency = currencies.sorted(limit)
+	summary.ByDepartment = departments.sorted(limit)
+
+	return summary
+}
+
+func inRange(data record.Record, from *time.Time, to *time.Time) bool {
+	if from == nil && to == nil {
+		return true
+	}
+
+	date, ok := data.ProcessDate()
+	if !ok {
+		return false
+	}
+	day := date.Format("2006-01-02")
+	if from != nil && day < from.Format("2006-01-02") {
+		return false
+	}
+	if to != nil && day > to.Format("2006-01-02") {
+		return false
+	}
+	return true
+}
+
+// amountPEN es el valor del proceso en soles, o cero si no se pudo convertir.
+func amountPEN(data record.Record) (money.Decimal, bool) {
+	if !data.AmountPEN.IsZero() {
+		return data.AmountPEN, true
+	}
+	if data.CurrencyCode == "PEN" && !data.Amount.IsZero() {
+		return data.Amount, true
+	}
+	return money.Decimal{}, false
+}
+
+func awardedPEN(data record.Record, item record.Item) (money.Decimal, bool) {
+	amount, err := money.ParseDecimal(item.AwardedAmount)
+	if err != nil {
+		return money.Decimal{}, false
+	}
+	if data.CurrencyCode == "PEN" {
+		return amount, true
+	}
+	if !data.ExchangeRate.IsZero() {
+		return amount.Mul(data.ExchangeRate), true
+	}
+	return money.Decimal{}, false
+}
+
+func (s *Share) add(flag record.Flag) {
+	switch flag {
+	case record.FlagYes:
+		s.Yes++
+	case record.FlagNo:
+		s.No++
+	default:
+		s.Unknown++
+	}
+}
+
+// Percent es el porcentaje de ganadores marcados con sí entre los que se
+// conoce el dato.
+func (s Share) Percent() float64 {
+	if s.Yes+s.No == 0 {
+		return 0
+	}
+	return float64(s.Yes) * 100 / float64(s.Yes+s.No)
+}
+
+// groups acumula los totales de una dimensión agrupando los nombres sin
+// importar tildes ni mayúsculas.
+type groups map[string]*Group
+
+func (g groups) get(name string) *Group {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = unknownGroup
+	}
+	key := record.Normalize(name)
+	if _, ok := g[key]; !ok {
+		g[key] = &Group{Name: name}
+	}
+	return g[key]
+}
+
+func (g groups) add(name string, items int, amount money.Decimal) {
+	group := g.get(name)
+	group.Processes++
+	group.Items += items
+	group.Amount = group.Amount.Add(amount)
+}
+
+func (g groups) item(name string, amount money.Decimal, newProcess bool) {
+	group := g.get(name)
+	if newProcess {
+		group.Processes++
+	}
+	group.Items++
+	group.Amount = group.Amount.Add(amount)
+}
+
+// sorted ordena los grupos por cantidad de procesos y luego por nombre.
+func (g groups) sorted(limit int) []Group {
+	result := make([]Group, 0, len(g))
+	for _, group := range g {
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Processes != result[j].Processes {
+			return result[i].Processes > result[j].Processes
+		}
+		return result[i].Name < result[j].Name
+	})
+
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,152 @@
+package stats
+
+import (
+	"dieg0407/seace/internal/money"
+	"dieg0407/seace/internal/record"
+	"testing"
+	"time"
+)
+
+func decimal(text string) money.Decimal {
+	value, err := money.ParseDecimal(text)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+func day(value string) *time.Time {
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return &parsed
+}
+
+func won(number int, winner string, awarded string, mype record.Flag) record.Item {
+	return record.Item{
+		Number:        number,
+		AwardedAmount: awarded,
+		Participants:  []record.Participant{{Name: winner, Awarded: "Ganador", IsAwarded: record.FlagYes, IsMYPE: mype}},
+	}
+}
+
+var fixture = []record.Record{
+	{
+		Entity:       "Municipalidad de Lima",
+		ObjectType:   "Bien",
+		Department:   "Lima",
+		CurrencyCode: "PEN",
+		Amount:       decimal("1000"),
+		Schedule:     []record.Stage{{Start: day("2024-03-01")}},
+		Items:        []record.Item{won(1, "ACME SAC", "900", record.FlagYes)},
+	},
+	{
+		Entity:       "MUNICIPALIDAD DE LIMA",
+		ObjectType:   "Servicio",
+		Department:   "Lima",
+		CurrencyCode: "USD",
+		Amount:       decimal("1000"),
+		AmountPEN:    decimal("3745"),
+		ExchangeRate: decimal("3.745"),
+		Schedule:     []record.Stage{{Start: day("2024-03-10")}},
+		Items: []record.Item{
+			won(1, "ACME SAC", "100", record.FlagUnknown),
+			won(2, "acme sac", "100", record.FlagUnknown),
+		},
+	},
+	{
+		Entity:       "Gobierno Regional de Puno",
+		ObjectType:   "Obra",
+		CurrencyCode: "USD",
+		Amount:       decimal("500"),
+		Schedule:     []record.Stage{{Start: day("2024-04-01")}},
+		Items:        []record.Item{{Number: 1, Status: "Desierto"}},
+	},
+}
+
+func TestCompute(t *testing.T) {
+	summary := Compute(fixture, nil, nil, 0)
+
+	totals := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"procesos", summary.Processes, 3},
+		{"items", summary.Items, 4},
+		{"adjudicados", summary.Awarded, 3},
+		{"monto", summary.Amount.String(), "4745.00"},
+		{"sin valor en soles", summary.WithoutPEN, 1},
+		{"mype", summary.MYPE, Share{Yes: 1, Unknown: 2}},
+		{"porcentaje mype", summary.MYPE.Percent(), 100.0},
+		{"selva", summary.Selva, Share{Unknown: 3}},
+	}
+	for _, total := range totals {
+		if total.got != total.want {
+			t.Errorf("%s = %v, se esperaba %v", total.name, total.got, total.want)
+		}
+	}
+
+	groups := []struct {
+		dimension []Group
+		want      []Group
+	}{
+		{
+			dimension: summary.ByEntity,
+			want: []Group{
+				{Name: "Municipalidad de Lima", Processes: 2, Items: 3, Amount: decimal("4745")},
+				{Name: "Gobierno Regional de Puno", Processes: 1, Items: 1, Amount: decimal("0")},
+			},
+		},
+		{
+			dimension: summary.ByWinner,
+			want:      []Group{{Name: "ACME SAC", Processes: 2, Items: 3, Amount: decimal("1649")}},
+		},
+		{
+			dimension: summary.ByDepartment,
+			want: []Group{
+				{Name: "Lima", Processes: 2, Items: 3, Amount: decimal("4745")},
+				{Name: unknownGroup, Processes: 1, Items: 1, Amount: decimal("0")},
+			},
+		},
+	}
+	for _, group := range groups {
+		if len(group.dimension) != len(group.want) {
+			t.Errorf("grupos = %+v, se esperaba %+v", group.dimension, group.want)
+			continue
+		}
+		for i, want := range group.want {
+			got := group.dimension[i]
+			if got.Name != want.Name || got.Processes != want.Processes || got.Items != want.Items || got.Amount.String() != want.Amount.String() {
+				t.Errorf("grupo %d = %+v, se esperaba %+v", i, got, want)
+			}
+		}
+	}
+}
+
+func TestComputeRangeAndLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		from      *time.Time
+		to        *time.Time
+		limit     int
+		processes int
+		entities  int
+	}{
+		{name: "todo", processes: 3, entities: 2},
+		{name: "desde", from: day("2024-03-10"), processes: 2, entities: 2},
+		{name: "hasta", to: day("2024-03-10"), processes: 2, entities: 1},
+		{name: "rango", from: day("2024-03-05"), to: day("2024-03-31"), processes: 1, entities: 1},
+		{name: "límite", limit: 1, processes: 3, entities: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			summary := Compute(fixture, test.from, test.to, test.limit)
+			if summary.Processes != test.processes || len(summary.ByEntity) != test.entities {
+				t.Errorf("Compute() = %d procesos y %d entidades, se esperaba %d y %d", summary.Processes, len(summary.ByEntity), test.processes, test.entities)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package store
+
+import (
+	"bufio"
+	"dieg0407/seace/internal/record"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// Entry es una versión de un proceso tal como se vio en una ejecución. Las
+// entradas sin registro solo confirman que la última versión seguía igual
+// cuando se volvió a ver.
+type Entry struct {
+	Key    string         `json:"clave"`
+	Hash   string         `json:"hash"`
+	Seen   time.Time      `json:"visto"`
+	Record *record.Record `json:"registro,omitempty"`
+}
+
+// Confirmation indica si la entrada solo confirma la versión anterior.
+func (e Entry) Confirmation() bool {
+	return e.Record == nil
+}
+
+// Store guarda los procesos vistos en ejecuciones anteriores en un archivo
+// con una línea JSON por cada vez que se vio un proceso: si cambió la línea
+// lleva la nueva versión y si no solo la clave, el hash y la fecha, así el
+// historial sabe hasta cuándo se vio cada versión. Solo se agregan líneas, así
+// que un corte a la mitad de una ejecución no daña lo que ya estaba guardado.
+type Store struct {
+	path   string
+	file   *os.File
+	latest map[string]Entry
+}
+
+func Open(path string) (*Store, error) {
+	store := &Store{path: path, latest: map[string]Entry{}}
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo abrir el almacén de procesos:\n%w", err)
+	}
+	store.file = file
+
+	return store, nil
+}
+
+// Load lee el almacén sin abrirlo para escritura.
+func Load(path string) (*Store, error) {
+	store := &Store{path: path, latest: map[string]Entry{}}
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *Store) load() error {
+	return s.scan(func(entry Entry) {
+		if !entry.Confirmation() {
+			s.latest[entry.Key] = entry
+		}
+	})
+}
+
+// scan recorre todas las versiones guardadas en el orden en que se vieron.
+func (s *Store) scan(visit func(Entry)) error {
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("no se pudo leer el almacén de procesos:\n%w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return fmt.Errorf("la línea %d del almacén de procesos está dañada:\n%w", line, err)
+		}
+		visit(entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("no se pudo leer el almacén de procesos:\n%w", err)
+	}
+
+	return nil
+}
+
+// Versions devuelve todas las entradas guardadas de un proceso, buscándolo
+// por su clave o por su nomenclatura, de la más antigua a la más reciente,
+// incluidas las que solo confirman una versión. Las versiones no se mantienen
+// en memoria, se leen del archivo.
+func (s *Store) Versions(process string) ([]Entry, error) {
+	nomenclature := record.Normalize(process)
+
+	versions := []Entry{}
+	keys := map[string]bool{}
+	err := s.scan(func(entry Entry) {
+		switch {
+		case entry.Confirmation():
+			if !keys[entry.Key] {
+				return
+			}
+		case entry.Key == process || record.Normalize(entry.Record.Nomenclature) == nomenclature:
+			keys[entry.Key] = true
+		default:
+			return
+		}
+		versions = append(versions, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool { return versions[i].Seen.Before(versions[j].Seen) })
+	return versions, nil
+}
+
+// Unchanged indica si el proceso ya está guardado con el mismo contenido.
+func (s *Store) Unchanged(data record.Record) bool {
+	if s == nil {
+		return false
+	}
+	entry, ok := s.latest[data.Key]
+	return ok && entry.Hash == data.Hash
+}
+
+// Put guarda una nueva versión del proceso si su contenido cambió, o una
+// entrada que confirma la última versión si no cambió.
+func (s *Store) Put(data record.Record) error {
+	if s == nil || data.Key == "" {
+		return nil
+	}
+
+	entry := Entry{Key: data.Key, Hash: data.Hash, Seen: time.Now()}
+	unchanged := s.Unchanged(data)
+	if !unchanged {
+		entry.Record = &data
+	}
+
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := s.file.Write(append(content, '\n')); err != nil {
+		return fmt.Errorf("no se pudo guardar el proceso en el almacén:\n%w", err)
+	}
+	if !unchanged {
+		s.latest[data.Key] = entry
+	}
+
+	return nil
+}
+
+func (s *Store) Get(key string) (Entry, bool) {
+	entry, ok := s.latest[key]
+	return entry, ok
+}
+
+// Records devuelve la última versión de cada proceso ordenada por clave.
+func (s *Store) Records() []record.Record {
+	keys := make([]string, 0, len(s.latest))
+	for key := range s.latest {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	records := make([]record.Record, 0, len(keys))
+	for _, key := range keys {
+		records = append(records, *s.latest[key].Record)
+	}
+	return records
+}
+
+func (s *Store) Close() error {
+	if s == nil || s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
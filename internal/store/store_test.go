@@ -0,0 +1,134 @@
+package store
+
+import (
+	"dieg0407/seace/internal/record"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func entry(key string, nomenclature string, hash string, seen string) Entry {
+	parsed, err := time.Parse(time.RFC3339, seen)
+	if err != nil {
+		panic(err)
+	}
+	return Entry{Key: key, Hash: hash, Seen: parsed, Record: &record.Record{Key: key, Nomenclature: nomenclature, Hash: hash}}
+}
+
+func confirmation(key string, hash string, seen string) Entry {
+	confirmed := entry(key, "", hash, seen)
+	confirmed.Record = nil
+	return confirmed
+}
+
+// writeEntries guarda las versiones en el orden dado, como si las hubieran
+// escrito varias ejecuciones.
+func writeEntries(t *testing.T, entries ...Entry) string {
+	path := filepath.Join(t.TempDir(), "almacen.jsonl")
+	content := []byte{}
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content = append(append(content, line...), '\n')
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestVersions(t *testing.T) {
+	path := writeEntries(t,
+		entry("nomenclatura:as-sm-1-2024-mpl-1", "AS-SM-1-2024-MPL-1", "b", "2024-03-05T10:00:00Z"),
+		entry("nomenclatura:as-sm-2-2024-mpl-1", "AS-SM-2-2024-MPL-1", "x", "2024-03-02T10:00:00Z"),
+		entry("nomenclatura:as-sm-1-2024-mpl-1", "AS-SM-1-2024-MPL-1", "a", "2024-03-01T10:00:00Z"),
+		entry("nomenclatura:as-sm-1-2024-mpl-1", "AS-SM-1-2024-MPL-1", "c", "2024-03-09T10:00:00Z"),
+		confirmation("nomenclatura:as-sm-2-2024-mpl-1", "x", "2024-03-10T10:00:00Z"),
+		confirmation("nomenclatura:as-sm-9-2024-mpl-1", "z", "2024-03-10T10:00:00Z"),
+	)
+	store, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		process string
+		want    []string
+	}{
+		{process: "nomenclatura:as-sm-1-2024-mpl-1", want: []string{"a", "b", "c"}},
+		{process: "as-sm-1-2024-mpl-1", want: []string{"a", "b", "c"}},
+		{process: " AS-SM-1-2024-MPL-1 ", want: []string{"a", "b", "c"}},
+		{process: "nomenclatura:as-sm-2-2024-mpl-1", want: []string{"x", "x"}},
+		{process: "nomenclatura:as-sm-9-2024-mpl-1", want: []string{}},
+		{process: "AS-SM-3-2024-MPL-1", want: []string{}},
+	}
+
+	for _, test := range tests {
+		versions, err := store.Versions(test.process)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashes := []string{}
+		for _, version := range versions {
+			hashes = append(hashes, version.Hash)
+		}
+		if len(hashes) != len(test.want) {
+			t.Errorf("Versions(%q) = %v, se esperaba %v", test.process, hashes, test.want)
+			continue
+		}
+		for i := range hashes {
+			if hashes[i] != test.want[i] {
+				t.Errorf("Versions(%q) = %v, se esperaba %v", test.process, hashes, test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestPutConfirmsUnchangedVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "almacen.jsonl")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, hash := range []string{"a", "a", "b"} {
+		if err := store.Put(record.Record{Key: "nomenclatura:as-sm-1-2024-mpl-1", Nomenclature: "AS-SM-1-2024-MPL-1", Hash: hash}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	key := "nomenclatura:as-sm-1-2024-mpl-1"
+	if !store.Unchanged(record.Record{Key: key, Hash: "b"}) || store.Unchanged(record.Record{Key: key, Hash: "a"}) {
+		t.Error("Unchanged() no compara con la última versión guardada")
+	}
+
+	reopened, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions, err := reopened.Versions(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 3 || versions[0].Hash != "a" || !versions[1].Confirmation() || versions[1].Hash != "a" || versions[2].Hash != "b" {
+		t.Errorf("Versions() = %+v, se esperaban la versión a, su confirmación y la versión b", versions)
+	}
+	if records := reopened.Records(); len(records) != 1 || records[0].Hash != "b" {
+		t.Errorf("Records() = %+v, se esperaba solo la versión b", records)
+	}
+}
+
+func TestLoadRejectsDamagedLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "almacen.jsonl")
+	if err := os.WriteFile(path, []byte("{\"clave\":\"seace:1\"}\n{clave\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Error("Load() no devolvió error con una línea dañada")
+	}
+}
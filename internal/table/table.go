@@ -0,0 +1,51 @@
+package table
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Read lee una tabla de configuración separada por `;`, con la primera fila
+// como cabecera y con comillas para los valores que contienen `;`. Llama a
+// visit con cada fila que no está vacía, con sus columnas sin espacios
+// alrededor y completadas hasta width. name describe la tabla en los errores.
+func Read(input io.Reader, name string, width int, visit func(line int, columns []string) error) error {
+	reader := csv.NewReader(input)
+	reader.Comma = ';'
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+
+	if _, err := reader.Read(); err != nil {
+		if err == io.EOF {
+			return nil
+		}
+		return fmt.Errorf("no se pudo leer %s:\n%w", name, err)
+	}
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("no se pudo leer %s:\n%w", name, err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		columns := make([]string, max(width, len(row)))
+		empty := true
+		for i, value := range row {
+			columns[i] = strings.TrimSpace(value)
+			empty = empty && columns[i] == ""
+		}
+		if empty {
+			continue
+		}
+
+		if err := visit(line, columns); err != nil {
+			return err
+		}
+	}
+}
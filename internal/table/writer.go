@@ -0,0 +1,47 @@
+package table
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// Writer escribe una tabla separada por `;` con encoding/csv, que pone entre
+// comillas y escapa los valores que lo necesitan, así que se puede volver a
+// leer con Read. La cabecera se escribe antes de la primera fila, o al cerrar
+// si no hubo filas.
+type Writer struct {
+	writer  *csv.Writer
+	columns []string
+	header  bool
+}
+
+func NewWriter(out io.Writer, columns []string) *Writer {
+	writer := csv.NewWriter(out)
+	writer.Comma = ';'
+	return &Writer{writer: writer, columns: columns}
+}
+
+// Write escribe las filas, precedidas de la cabecera si todavía no se
+// escribió.
+func (w *Writer) Write(rows ...[]string) error {
+	if !w.header {
+		w.header = true
+		if err := w.writer.Write(w.columns); err != nil {
+			return err
+		}
+	}
+
+	for _, row := range rows {
+		if err := w.writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// Close escribe la cabecera si la tabla quedó vacía.
+func (w *Writer) Close() error {
+	return w.Write()
+}
@@ -0,0 +1,23 @@
+package text
+
+import "strings"
+
+var accents = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n",
+	"à", "a", "è", "e", "ì", "i", "ò", "o", "ù", "u",
+	"Á", "A", "É", "E", "Í", "I", "Ó", "O", "Ú", "U", "Ü", "U", "Ñ", "N",
+	"À", "A", "È", "E", "Ì", "I", "Ò", "O", "Ù", "U",
+)
+
+// RemoveAccents quita las tildes sin cambiar mayúsculas ni espacios, para los
+// textos que no se pueden normalizar del todo, como las expresiones regulares.
+func RemoveAccents(value string) string {
+	return accents.Replace(value)
+}
+
+// Normalize quita tildes, espacios repetidos y mayúsculas para comparar
+// textos del portal.
+func Normalize(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	return strings.Join(strings.Fields(accents.Replace(value)), " ")
+}
@@ -0,0 +1,25 @@
+package text
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"  Dólares   Americanos ": "dolares americanos",
+		"CONSTRUCCIÓN":            "construccion",
+		"Año Ñandú":               "ano nandu",
+		"Pingüino":                "pinguino",
+		"":                        "",
+	}
+
+	for value, want := range tests {
+		if got := Normalize(value); got != want {
+			t.Errorf("Normalize(%q) = %q, se esperaba %q", value, got, want)
+		}
+	}
+}
+
+func TestRemoveAccents(t *testing.T) {
+	if got, want := RemoveAccents(`\SCONSTRUCCIÓN  de \Wvías`), `\SCONSTRUCCION  de \Wvias`; got != want {
+		t.Errorf("RemoveAccents() = %q, se esperaba %q", got, want)
+	}
+}
@@ -0,0 +1,273 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"dieg0407/seace/internal/alerts"
+	"dieg0407/seace/internal/record"
+	"dieg0407/seace/internal/run"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Seace-Firma"
+	EventHeader     = "X-Seace-Evento"
+	DeliveryHeader  = "X-Seace-Entrega"
+)
+
+// Payload es el cuerpo JSON que reciben los endpoints.
+type Payload struct {
+	Event    string    `json:"evento"`
+	Delivery string    `json:"entrega"`
+	Sent     time.Time `json:"enviado"`
+	Data     any       `json:"datos"`
+}
+
+// Attempt es un intento de entrega, tal como queda en el registro de
+// entregas.
+type Attempt struct {
+	Delivery string    `json:"entrega"`
+	Event    string    `json:"evento"`
+	URL      string    `json:"url"`
+	Number   int       `json:"intento"`
+	Status   int       `json:"estado,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Date     time.Time `json:"fecha"`
+}
+
+type event struct {
+	name string
+	data any
+}
+
+// Dispatcher envía los eventos a los endpoints suscritos. Sirve como
+// record.Writer para el evento registro, como alerts.Notifier para el evento
+// alerta y como run.Reporter para el resumen de la ejecución. Los eventos se
+// encolan y se envían en orden desde otra goroutine, así que un endpoint lento
+// no frena la extracción; Close espera a que la cola se vacíe.
+type Dispatcher struct {
+	endpoints  []Endpoint
+	client     *http.Client
+	attempts   int
+	backoff    time.Duration
+	deliveries io.Writer
+	logger     *log.Logger
+
+	mutex   sync.Mutex
+	pending *sync.Cond
+	queue   []event
+	closed  bool
+	done    chan struct{}
+}
+
+// NewDispatcher crea un dispatcher que hace hasta 4 intentos por entrega,
+// esperando 2s, 4s y 8s entre ellos. Cada intento se escribe como una línea
+// JSON en deliveries, si no es nil.
+func NewDispatcher(endpoints []Endpoint, deliveries io.Writer, logger *log.Logger) *Dispatcher {
+	d := &Dispatcher{
+		endpoints:  endpoints,
+		client:     &http.Client{Timeout: 30 * time.Second},
+		attempts:   4,
+		backoff:    2 * time.Second,
+		deliveries: deliveries,
+		logger:     logger,
+		done:       make(chan struct{}),
+	}
+	d.pending = sync.NewCond(&d.mutex)
+	go d.send()
+	return d
+}
+
+// Subscribed indica si algún endpoint recibe el evento.
+func (d *Dispatcher) Subscribed(event string) bool {
+	for _, endpoint := range d.endpoints {
+		if endpoint.Subscribed(event) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Dispatcher) RunFinished(summary run.Summary) error {
+	return d.enqueue(EventRun, summary)
+}
+
+// Write encola el registro. Un endpoint caído no detiene la extracción, así
+// que los errores de entrega solo quedan en el log.
+func (d *Dispatcher) Write(data record.Record) error {
+	return d.enqueue(EventRecord, data)
+}
+
+func (d *Dispatcher) Notify(alert alerts.Alert) error {
+	return d.enqueue(EventAlert, alert)
+}
+
+// Close deja de aceptar eventos y espera a que se entreguen los encolados.
+func (d *Dispatcher) Close() error {
+	d.mutex.Lock()
+	d.closed = true
+	d.pending.Broadcast()
+	d.mutex.Unlock()
+
+	<-d.done
+	return nil
+}
+
+func (d *Dispatcher) enqueue(name string, data any) error {
+	if !d.Subscribed(name) {
+		return nil
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.closed {
+		return fmt.Errorf("no se pudo encolar el evento %s, los webhooks ya se cerraron", name)
+	}
+	d.queue = append(d.queue, event{name: name, data: data})
+	d.pending.Signal()
+	return nil
+}
+
+// send entrega los eventos de la cola hasta que se cierra y se vacía.
+func (d *Dispatcher) send() {
+	defer close(d.done)
+	for {
+		d.mutex.Lock()
+		for len(d.queue) == 0 && !d.closed {
+			d.pending.Wait()
+		}
+		if len(d.queue) == 0 {
+			d.mutex.Unlock()
+			return
+		}
+		next := d.queue[0]
+		d.queue = d.queue[1:]
+		d.mutex.Unlock()
+
+		if err := d.dispatch(next.name, next.data); err != nil {
+			d.logger.Printf("%v\n", err)
+		}
+	}
+}
+
+// dispatch entrega el evento a cada endpoint suscrito y devuelve un error si
+// alguno no lo recibió.
+func (d *Dispatcher) dispatch(event string, data any) error {
+	failed := 0
+	for _, endpoint := range d.endpoints {
+		if !endpoint.Subscribed(event) {
+			continue
+		}
+		if err := d.deliver(endpoint, event, data); err != nil {
+			d.logger.Printf("No se pudo entregar el evento %s a %s:\n%v\n", event, endpoint.URL, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("el evento %s no se entregó a %d webhooks", event, failed)
+	}
+	return nil
+}
+
+// deliver envía el evento al endpoint y reintenta con espera exponencial
+// mientras el error sea de red, 429 o 5xx.
+func (d *Dispatcher) deliver(endpoint Endpoint, event string, data any) error {
+	payload := Payload{Event: event, Delivery: newDeliveryID(), Sent: time.Now(), Data: data}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("no se pudo serializar el evento:\n%w", err)
+	}
+	signature := Sign(endpoint.Secret, body)
+
+	wait := d.backoff
+	for number := 1; ; number++ {
+		status, err := d.post(endpoint.URL, event, payload.Delivery, signature, body)
+		d.record(Attempt{Delivery: payload.Delivery, Event: event, URL: endpoint.URL, Number: number, Status: status, Error: errorText(err), Date: time.Now()})
+		if err == nil {
+			d.logger.Printf("Evento %s entregado a %s (entrega %s, intento %d)\n", event, endpoint.URL, payload.Delivery, number)
+			return nil
+		}
+		if !retryable(status) || number >= d.attempts {
+			return err
+		}
+
+		d.logger.Printf("Falló el intento %d de entregar el evento %s a %s, se reintenta en %s\n", number, event, endpoint.URL, wait)
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+func (d *Dispatcher) post(url string, event string, delivery string, signature string, body []byte) (int, error) {
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(SignatureHeader, signature)
+	request.Header.Set(EventHeader, event)
+	request.Header.Set(DeliveryHeader, delivery)
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("el endpoint respondió %s", response.Status)
+	}
+	return response.StatusCode, nil
+}
+
+func (d *Dispatcher) record(attempt Attempt) {
+	if d.deliveries == nil {
+		return
+	}
+	line, err := json.Marshal(attempt)
+	if err != nil {
+		return
+	}
+	if _, err := d.deliveries.Write(append(line, '\n')); err != nil {
+		d.logger.Printf("No se pudo escribir en el registro de entregas:\n%v\n", err)
+	}
+}
+
+// Sign devuelve la firma que va en la cabecera X-Seace-Firma: el HMAC-SHA256
+// del cuerpo con el secreto del endpoint, en hexadecimal y con el prefijo
+// sha256=.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryable indica si vale la pena reintentar. Un estado 0 es un error de red.
+func retryable(status int) bool {
+	return status == 0 || status == http.StatusTooManyRequests || status >= 500
+}
+
+func newDeliveryID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(id)
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,120 @@
+package webhook
+
+import (
+	"bytes"
+	"dieg0407/seace/internal/alerts"
+	"dieg0407/seace/internal/record"
+	"dieg0407/seace/internal/run"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		secret string
+		body   string
+		want   string
+	}{
+		{secret: "", body: "", want: "sha256=b613679a0814d9ec772f95d778c35fc5ff1697c493715653c6c712144292c5ad"},
+		{secret: "key", body: "The quick brown fox jumps over the lazy dog", want: "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"},
+	}
+
+	for _, test := range tests {
+		if got := Sign(test.secret, []byte(test.body)); got != test.want {
+			t.Errorf("Sign(%q, %q) = %s, se esperaba %s", test.secret, test.body, got, test.want)
+		}
+	}
+	if Sign("a", []byte("cuerpo")) == Sign("b", []byte("cuerpo")) {
+		t.Error("Sign() devolvió la misma firma con secretos distintos")
+	}
+}
+
+// endpointServer responde con los estados indicados, uno por pedido, y 200
+// cuando se acaban.
+type endpointServer struct {
+	mutex    sync.Mutex
+	statuses []int
+	events   []string
+	bodies   [][]byte
+}
+
+func (s *endpointServer) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	body, _ := io.ReadAll(request.Body)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if request.Header.Get(SignatureHeader) != Sign("secreto", body) {
+		response.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	status := http.StatusOK
+	if len(s.statuses) > 0 {
+		status, s.statuses = s.statuses[0], s.statuses[1:]
+	}
+	if status == http.StatusOK {
+		s.events = append(s.events, request.Header.Get(EventHeader))
+		s.bodies = append(s.bodies, body)
+	}
+	response.WriteHeader(status)
+}
+
+func TestDispatcher(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		events   []string
+		attempts int
+	}{
+		{name: "entregado", events: []string{EventRecord, EventRun}, attempts: 2},
+		{name: "reintenta 5xx y 429", statuses: []int{500, 429}, events: []string{EventRecord, EventRun}, attempts: 4},
+		{name: "no reintenta 4xx", statuses: []int{400}, events: []string{EventRun}, attempts: 2},
+		{name: "agota los intentos", statuses: []int{503, 503, 503, 503}, events: []string{EventRun}, attempts: 5},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := &endpointServer{statuses: test.statuses}
+			httpServer := httptest.NewServer(server)
+			defer httpServer.Close()
+
+			deliveries := &bytes.Buffer{}
+			endpoints := []Endpoint{{URL: httpServer.URL, Secret: "secreto", Events: map[string]bool{EventRun: true, EventRecord: true}}}
+			dispatcher := NewDispatcher(endpoints, deliveries, log.New(io.Discard, "", 0))
+			dispatcher.backoff = time.Millisecond
+
+			if err := dispatcher.Write(record.Record{Key: "seace:1"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := dispatcher.Notify(alerts.Alert{Key: "seace:1"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := dispatcher.RunFinished(run.Summary{Run: "prueba"}); err != nil {
+				t.Fatal(err)
+			}
+			dispatcher.Close()
+
+			if strings.Join(server.events, ",") != strings.Join(test.events, ",") {
+				t.Errorf("eventos entregados = %v, se esperaba %v", server.events, test.events)
+			}
+			if lines := strings.Count(deliveries.String(), "\n"); lines != test.attempts {
+				t.Errorf("intentos registrados = %d, se esperaba %d", lines, test.attempts)
+			}
+			for _, body := range server.bodies {
+				var payload Payload
+				if err := json.Unmarshal(body, &payload); err != nil || payload.Delivery == "" {
+					t.Errorf("cuerpo inválido %s: %v", body, err)
+				}
+			}
+			if err := dispatcher.Write(record.Record{}); err == nil {
+				t.Error("Write() después de Close() no devolvió un error")
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"dieg0407/seace/internal/table"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	EventRun    = "ejecucion"
+	EventRecord = "registro"
+	EventAlert  = "alerta"
+)
+
+// Endpoint es una URL que recibe los eventos a los que está suscrita,
+// firmados con su secreto.
+type Endpoint struct {
+	URL    string
+	Secret string
+	Events map[string]bool
+}
+
+// Subscribed indica si el endpoint recibe el evento.
+func (e Endpoint) Subscribed(event string) bool {
+	return e.Events[event]
+}
+
+// LoadEndpoints lee la lista de webhooks con las columnas url;secreto;eventos.
+// Los eventos se separan con `|`; si la columna está vacía solo se envía el
+// resumen de la ejecución.
+func LoadEndpoints(path string) ([]Endpoint, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo abrir la lista de webhooks:\n%w", err)
+	}
+	defer file.Close()
+
+	return parseEndpoints(file)
+}
+
+func parseEndpoints(input io.Reader) ([]Endpoint, error) {
+	endpoints := []Endpoint{}
+
+	err := table.Read(input, "la lista de webhooks", 3, func(line int, columns []string) error {
+		endpoint := Endpoint{URL: columns[0], Secret: columns[1], Events: map[string]bool{}}
+		parsed, err := url.Parse(endpoint.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("la línea %d de la lista de webhooks no tiene una url http válida", line)
+		}
+		if endpoint.Secret == "" {
+			return fmt.Errorf("la línea %d de la lista de webhooks no tiene secreto", line)
+		}
+
+		for _, event := range strings.Split(columns[2], "|") {
+			event = strings.ToLower(strings.TrimSpace(event))
+			switch event {
+			case "":
+				continue
+			case EventRun, EventRecord, EventAlert:
+				endpoint.Events[event] = true
+			default:
+				return fmt.Errorf("la línea %d de la lista de webhooks tiene un evento desconocido: %s", line, event)
+			}
+		}
+		if len(endpoint.Events) == 0 {
+			endpoint.Events[EventRun] = true
+		}
+
+		endpoints = append(endpoints, endpoint)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return endpoints, nil
+}
@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseEndpoints(t *testing.T) {
+	tests := []struct {
+		name    string
+		table   string
+		want    map[string]bool
+		wantErr bool
+	}{
+		{
+			name:  "sin eventos",
+			table: "url;secreto;eventos\nhttps://example.com/seace;secreto;\n",
+			want:  map[string]bool{EventRun: true},
+		},
+		{
+			name:  "varios eventos",
+			table: "url;secreto;eventos\nhttps://example.com/seace;secreto; Registro | alerta \n",
+			want:  map[string]bool{EventRecord: true, EventAlert: true},
+		},
+		{name: "url sin esquema", table: "url;secreto;eventos\nexample.com/seace;secreto;\n", wantErr: true},
+		{name: "esquema no http", table: "url;secreto;eventos\nftp://example.com;secreto;\n", wantErr: true},
+		{name: "sin secreto", table: "url;secreto;eventos\nhttps://example.com/seace;;\n", wantErr: true},
+		{name: "evento desconocido", table: "url;secreto;eventos\nhttps://example.com/seace;secreto;cierre\n", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			endpoints, err := parseEndpoints(strings.NewReader(test.table))
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("parseEndpoints() = %+v, se esperaba un error", endpoints)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(endpoints) != 1 || !reflect.DeepEqual(endpoints[0].Events, test.want) {
+				t.Errorf("parseEndpoints() = %+v, se esperaban los eventos %v", endpoints, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+#!/bin/sh
+
+# run from the script folder, where the scrapper and the queue are
+cd "$(dirname "$0")" || exit 1
+
+execution_date=$(date +"%Y-%m-%d")
+
+if [ ! -d "logs" ]; then 
+    mkdir logs
+fi
+
+if [ ! -d "reports" ]; then 
+    mkdir reports
+fi
+
+log_path="logs/$execution_date.seguimiento.log"
+report_path="reports/seguimiento-$execution_date.csv"
+
+./scrapper seguimiento --cola cola-seguimiento.json > $report_path 2> $log_path
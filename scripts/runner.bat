@@ -4,4 +4,4 @@ set /p execution_date=Ingresa la fecha a procesar (yyyy-MM-dd):
 
 if not exist reportes mkdir reportes
 
-scrapper.exe -d %execution_date% > reportes\reporte-%execution_date%.csv
+scrapper.exe -d %execution_date% --cola cola-seguimiento.json > reportes\reporte-%execution_date%.csv
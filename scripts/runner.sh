@@ -1,5 +1,8 @@
 #!/bin/sh
 
+# run from the script folder, where the scrapper and the queue are
+cd "$(dirname "$0")" || exit 1
+
 execution_date=$1
 
 # check if date is empty
@@ -18,4 +21,4 @@ fi
 log_path="logs/$execution_date.execution.log"
 report_path="reports/reportes-$execution_date.csv"
 
-./scrapper -d $execution_date > $report_path 2> $log_path
+./scrapper -d $execution_date --cola cola-seguimiento.json > $report_path 2> $log_path